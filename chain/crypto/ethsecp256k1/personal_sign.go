@@ -0,0 +1,51 @@
+package ethsecp256k1
+
+import (
+	"fmt"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// SignatureSize defines the size of a recoverable [R || S || V] signature
+	SignatureSize = 65
+
+	// personalSignPrefix is the EIP-191 version 0x45 prefix prepended by personal_sign
+	personalSignPrefix = "\x19Ethereum Signed Message:\n"
+)
+
+// PersonalSignPreimage returns the EIP-191 personal_sign message of msg, i.e. msg prefixed with
+// "\x19Ethereum Signed Message:\n" and its length. The personal_sign hash is its Keccak256 hash.
+func PersonalSignPreimage(msg []byte) []byte {
+	prefix := fmt.Sprintf("%s%d", personalSignPrefix, len(msg))
+	return append([]byte(prefix), msg...)
+}
+
+// PersonalSignHash returns the EIP-191 personal_sign hash of msg.
+func PersonalSignHash(msg []byte) []byte {
+	return ethcrypto.Keccak256(PersonalSignPreimage(msg))
+}
+
+// SignPersonal signs msg the way Ethereum wallets do for personal_sign. The produced signature
+// is 65 bytes where the last byte contains the recovery ID offset by 27.
+func (privKey PrivKey) SignPersonal(msg []byte) ([]byte, error) {
+	// Sign hashes the preimage with Keccak256
+	sig, err := privKey.Sign(PersonalSignPreimage(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	sig[SignatureSize-1] += 27
+	return sig, nil
+}
+
+// VerifyPersonalSignature verifies that the public key created a personal_sign signature over msg.
+func (pubKey PubKey) VerifyPersonalSignature(msg []byte, sig []byte) bool {
+	return pubKey.VerifySignature(PersonalSignPreimage(msg), sig)
+}
+
+// RecoverPersonalSignPubKey recovers the public key from a 65 byte personal_sign signature over msg.
+// The recovery ID may be either 0/1 or 27/28, as returned by Ethereum wallets.
+func RecoverPersonalSignPubKey(msg []byte, sig []byte) (*PubKey, error) {
+	return recoverPubKey(PersonalSignHash(msg), sig)
+}
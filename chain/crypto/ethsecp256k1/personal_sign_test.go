@@ -0,0 +1,67 @@
+package ethsecp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// testPrivKey is the key of the web3.js eth.accounts.sign example
+func testPrivKey(t *testing.T) *PrivKey {
+	t.Helper()
+
+	bz, err := hex.DecodeString("4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &PrivKey{Key: bz}
+}
+
+func TestSignPersonalMatchesWallets(t *testing.T) {
+	privKey := testPrivKey(t)
+
+	sig, err := privKey.SignPersonal([]byte("Some data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c"
+	if hex.EncodeToString(sig) != expected {
+		t.Fatalf("unexpected personal_sign signature %x", sig)
+	}
+
+	pubKey, err := RecoverPersonalSignPubKey([]byte("Some data"), sig)
+	if err != nil {
+		t.Fatal(err)
+	} else if addr := pubKey.HexAddress(); addr != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Fatalf("unexpected signer address %s", addr)
+	}
+}
+
+func TestVerifyPersonalSignature(t *testing.T) {
+	privKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := privKey.PubKey().(*PubKey)
+
+	sig, err := privKey.SignPersonal([]byte("login"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !pubKey.VerifyPersonalSignature([]byte("login"), sig) {
+		t.Fatal("expected the personal_sign signature to be valid")
+	} else if pubKey.VerifyPersonalSignature([]byte("logout"), sig) {
+		t.Fatal("expected the signature of another message to be invalid")
+	} else if pubKey.VerifySignature([]byte("login"), sig) {
+		t.Fatal("expected a personal_sign signature not to be valid over the raw message")
+	}
+}
+
+func TestPersonalSignPreimage(t *testing.T) {
+	if preimage := PersonalSignPreimage([]byte("hello")); !bytes.Equal(preimage, []byte("\x19Ethereum Signed Message:\n5hello")) {
+		t.Fatalf("unexpected preimage %q", preimage)
+	}
+}
@@ -0,0 +1,128 @@
+package ethsecp256k1
+
+import (
+	"bytes"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// SignedMessage is a message with its 65 byte signature, created by the key of Address.
+type SignedMessage struct {
+	Msg     []byte
+	Sig     []byte
+	Address sdk.AccAddress
+}
+
+// RecoverPubKey recovers the public key from a 65 byte signature created by PrivKey.Sign over msg,
+// i.e. over the Keccak256 hash of msg. The recovery ID may be either 0/1 or 27/28.
+func RecoverPubKey(msg []byte, sig []byte) (*PubKey, error) {
+	return recoverPubKey(ethcrypto.Keccak256(msg), sig)
+}
+
+// RecoverAddress recovers the address of the key that created the signature over msg.
+func RecoverAddress(msg []byte, sig []byte) (sdk.AccAddress, error) {
+	pubKey, err := RecoverPubKey(msg, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdk.AccAddress(pubKey.Address()), nil
+}
+
+// RecoverBech32Address recovers the bech32 address with the prefix, e.g. "gio",
+// of the key that created the signature over msg.
+func RecoverBech32Address(msg []byte, sig []byte, bech32Prefix string) (string, error) {
+	addr, err := RecoverAddress(msg, sig)
+	if err != nil {
+		return "", err
+	}
+
+	return sdk.Bech32ifyAddressBytes(bech32Prefix, addr)
+}
+
+// RecoverHexAddress recovers the EIP-55 checksummed 0x address of the key that created the signature over msg.
+func RecoverHexAddress(msg []byte, sig []byte) (string, error) {
+	addr, err := RecoverAddress(msg, sig)
+	if err != nil {
+		return "", err
+	}
+
+	return ethcommon.BytesToAddress(addr).Hex(), nil
+}
+
+// HexAddress returns the EIP-55 checksummed 0x address of the public key.
+func (pubKey PubKey) HexAddress() string {
+	return ethcommon.BytesToAddress(pubKey.Address()).Hex()
+}
+
+// VerifyAddressSignature verifies that the key of the address created the signature over msg,
+// for signers whose public key isn't known, e.g. not yet on chain.
+func VerifyAddressSignature(addr sdk.AccAddress, msg []byte, sig []byte) bool {
+	recovered, err := RecoverAddress(msg, sig)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(recovered, addr)
+}
+
+// BatchVerify verifies the signatures of the messages against their addresses. It returns
+// an error for each message, which is nil if its signature is valid.
+func BatchVerify(msgs []SignedMessage) []error {
+	errs := make([]error, len(msgs))
+	for idx, msg := range msgs {
+		recovered, err := RecoverAddress(msg.Msg, msg.Sig)
+		if err != nil {
+			errs[idx] = err
+		} else if !bytes.Equal(recovered, msg.Address) {
+			errs[idx] = fmt.Errorf("signature of message %d is from %s, expected %s", idx, recovered, msg.Address)
+		}
+	}
+
+	return errs
+}
+
+// BatchRecoverPubKeys recovers the public keys of the signatures over the messages, which must be of the same length.
+func BatchRecoverPubKeys(msgs [][]byte, sigs [][]byte) ([]*PubKey, error) {
+	if len(msgs) != len(sigs) {
+		return nil, fmt.Errorf("got %d messages and %d signatures", len(msgs), len(sigs))
+	}
+
+	pubKeys := make([]*PubKey, 0, len(msgs))
+	for idx := range msgs {
+		pubKey, err := RecoverPubKey(msgs[idx], sigs[idx])
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", idx, err)
+		}
+
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	return pubKeys, nil
+}
+
+// recoverPubKey recovers the compressed public key from a 65 byte signature over the hash.
+func recoverPubKey(hash []byte, sig []byte) (*PubKey, error) {
+	if len(sig) != SignatureSize {
+		return nil, fmt.Errorf("invalid signature size, expected %d got %d", SignatureSize, len(sig))
+	}
+
+	// normalize the Ethereum wallet recovery ID without modifying the caller's signature
+	recoverable := make([]byte, SignatureSize)
+	copy(recoverable, sig)
+	if v := recoverable[SignatureSize-1]; v == 27 || v == 28 {
+		recoverable[SignatureSize-1] = v - 27
+	}
+
+	pubk, err := ethcrypto.SigToPub(hash, recoverable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover pubkey: %w", err)
+	}
+
+	return &PubKey{
+		Key: ethcrypto.CompressPubkey(pubk),
+	}, nil
+}
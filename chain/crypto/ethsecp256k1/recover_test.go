@@ -0,0 +1,123 @@
+package ethsecp256k1
+
+import (
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestRecoverPubKey(t *testing.T) {
+	privKey, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKey := privKey.PubKey()
+
+	sig, err := privKey.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recovered, err := RecoverPubKey([]byte("msg"), sig)
+	if err != nil {
+		t.Fatal(err)
+	} else if !recovered.Equals(pubKey) {
+		t.Fatal("expected the pubkey of the signer to be recovered")
+	}
+
+	// Ethereum wallets offset the recovery ID by 27
+	walletSig := append([]byte(nil), sig...)
+	walletSig[SignatureSize-1] += 27
+	if recovered, err := RecoverPubKey([]byte("msg"), walletSig); err != nil || !recovered.Equals(pubKey) {
+		t.Fatalf("expected the pubkey to be recovered from the wallet signature: %v", err)
+	} else if walletSig[SignatureSize-1] != sig[SignatureSize-1]+27 {
+		t.Fatal("expected the signature not to be modified")
+	}
+
+	if _, err := RecoverPubKey([]byte("msg"), sig[:64]); err == nil {
+		t.Fatal("expected a signature without recovery ID to be rejected")
+	}
+}
+
+func TestRecoverAddresses(t *testing.T) {
+	privKey := testPrivKey(t)
+	sig, err := privKey.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if addr, err := RecoverHexAddress([]byte("msg"), sig); err != nil || addr != "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23" {
+		t.Fatalf("unexpected hex address %s: %v", addr, err)
+	}
+
+	addr, err := RecoverBech32Address([]byte("msg"), sig, "gio")
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(addr, "gio1") {
+		t.Fatalf("expected a gio address, got %s", addr)
+	}
+
+	bz, err := sdk.GetFromBech32(addr, "gio")
+	if err != nil {
+		t.Fatal(err)
+	} else if !sdk.AccAddress(bz).Equals(sdk.AccAddress(privKey.PubKey().Address())) {
+		t.Fatal("expected the bech32 address of the signer")
+	}
+}
+
+func TestVerifyAddressSignature(t *testing.T) {
+	privKey := testPrivKey(t)
+	addr := sdk.AccAddress(privKey.PubKey().Address())
+
+	sig, err := privKey.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !VerifyAddressSignature(addr, []byte("msg"), sig) {
+		t.Fatal("expected the signature of the address to be valid")
+	} else if VerifyAddressSignature(addr, []byte("other"), sig) {
+		t.Fatal("expected the signature of another message to be invalid")
+	} else if VerifyAddressSignature(addr, []byte("msg"), sig[:10]) {
+		t.Fatal("expected a truncated signature to be invalid")
+	}
+}
+
+func TestBatchVerify(t *testing.T) {
+	privKey := testPrivKey(t)
+	other, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := privKey.Sign([]byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSig, err := other.Sign([]byte("b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := sdk.AccAddress(privKey.PubKey().Address())
+	errs := BatchVerify([]SignedMessage{
+		{Msg: []byte("a"), Sig: sig, Address: addr},
+		{Msg: []byte("b"), Sig: otherSig, Address: addr},
+		{Msg: []byte("c"), Sig: []byte{1}, Address: addr},
+	})
+	if len(errs) != 3 || errs[0] != nil || errs[1] == nil || errs[2] == nil {
+		t.Fatalf("expected only the first signature to be valid, got %v", errs)
+	}
+
+	pubKeys, err := BatchRecoverPubKeys([][]byte{[]byte("a"), []byte("b")}, [][]byte{sig, otherSig})
+	if err != nil {
+		t.Fatal(err)
+	} else if !pubKeys[0].Equals(privKey.PubKey()) || !pubKeys[1].Equals(other.PubKey()) {
+		t.Fatal("expected the pubkeys of the signers")
+	}
+
+	if _, err := BatchRecoverPubKeys([][]byte{[]byte("a")}, nil); err == nil {
+		t.Fatal("expected messages without signatures to be rejected")
+	}
+}
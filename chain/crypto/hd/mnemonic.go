@@ -0,0 +1,99 @@
+package hd
+
+import (
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// EthCoinType is the BIP-44 coin type of Ethereum, used with eth_secp256k1 keys
+	EthCoinType uint32 = 60
+	// CosmosCoinType is the BIP-44 coin type of the Cosmos Hub, used with secp256k1 keys
+	CosmosCoinType uint32 = sdk.CoinType
+
+	// MnemonicEntropySize12 and MnemonicEntropySize24 are the entropy sizes of 12 and 24 word mnemonics
+	MnemonicEntropySize12 = 128
+	MnemonicEntropySize24 = 256
+)
+
+// DerivedAccount is a key derived from a mnemonic at an HD path.
+type DerivedAccount struct {
+	HDPath  string
+	PrivKey cryptotypes.PrivKey
+	Address sdk.AccAddress
+}
+
+// NewMnemonic generates a new BIP-39 mnemonic with the entropy size in bits,
+// MnemonicEntropySize24 for a 24 word mnemonic.
+func NewMnemonic(entropySize int) (string, error) {
+	entropy, err := bip39.NewEntropy(entropySize)
+	if err != nil {
+		err = errors.Wrap(err, "failed to generate mnemonic entropy")
+		return "", err
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		err = errors.Wrap(err, "failed to generate mnemonic")
+		return "", err
+	}
+
+	return mnemonic, nil
+}
+
+// CoinTypeFor returns the default BIP-44 coin type of the signing algorithm.
+func CoinTypeFor(algo keyring.SignatureAlgo) uint32 {
+	if algo.Name() == EthSecp256k1Type {
+		return EthCoinType
+	}
+
+	return CosmosCoinType
+}
+
+// BIP44Path returns the BIP-44 path m/44'/coinType'/account'/0/index.
+func BIP44Path(coinType, account, index uint32) string {
+	return hd.CreateHDPath(coinType, account, index).String()
+}
+
+// DerivePrivKey derives the private key of the signing algorithm, EthSecp256k1 or
+// hd.Secp256k1, from the mnemonic at an arbitrary BIP-44 path.
+func DerivePrivKey(algo keyring.SignatureAlgo, mnemonic, bip39Passphrase, hdPath string) (cryptotypes.PrivKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+
+	derivedKey, err := algo.Derive()(mnemonic, bip39Passphrase, hdPath)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to derive %s key at %s", algo.Name(), hdPath)
+		return nil, err
+	}
+
+	return algo.Generate()(derivedKey), nil
+}
+
+// DeriveAccounts derives n accounts from the mnemonic at consecutive address indexes
+// of the BIP-44 account, using the default coin type of the signing algorithm.
+func DeriveAccounts(algo keyring.SignatureAlgo, mnemonic, bip39Passphrase string, account uint32, n int) ([]DerivedAccount, error) {
+	coinType := CoinTypeFor(algo)
+
+	accounts := make([]DerivedAccount, 0, n)
+	for idx := 0; idx < n; idx++ {
+		hdPath := BIP44Path(coinType, account, uint32(idx))
+		privKey, err := DerivePrivKey(algo, mnemonic, bip39Passphrase, hdPath)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, DerivedAccount{
+			HDPath:  hdPath,
+			PrivKey: privKey,
+			Address: sdk.AccAddress(privKey.PubKey().Address()),
+		})
+	}
+
+	return accounts, nil
+}
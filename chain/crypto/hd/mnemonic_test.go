@@ -0,0 +1,93 @@
+package hd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/tyler-smith/go-bip39"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestNewMnemonic(t *testing.T) {
+	for entropySize, words := range map[int]int{
+		MnemonicEntropySize12: 12,
+		MnemonicEntropySize24: 24,
+	} {
+		mnemonic, err := NewMnemonic(entropySize)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(strings.Fields(mnemonic)) != words || !bip39.IsMnemonicValid(mnemonic) {
+			t.Fatalf("expected a valid %d word mnemonic, got %q", words, mnemonic)
+		}
+	}
+
+	if _, err := NewMnemonic(100); err == nil {
+		t.Fatal("expected an invalid entropy size to be rejected")
+	}
+}
+
+func TestDerivePrivKey(t *testing.T) {
+	// the well-known first Ethereum account of the mnemonic
+	privKey, err := DerivePrivKey(EthSecp256k1, testMnemonic, "", "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	} else if addr := ethcommon.BytesToAddress(privKey.PubKey().Address()); addr.Hex() != "0x9858EfFD232B4033E47d90003D41EC34EcaEda94" {
+		t.Fatalf("unexpected eth_secp256k1 address %s", addr.Hex())
+	}
+
+	cosmosKey, err := DerivePrivKey(hd.Secp256k1, testMnemonic, "", "m/44'/118'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	} else if cosmosKey.Type() != "secp256k1" {
+		t.Fatalf("expected a secp256k1 key, got %s", cosmosKey.Type())
+	}
+
+	if _, err := DerivePrivKey(EthSecp256k1, "abandon abandon", "", "m/44'/60'/0'/0/0"); err == nil {
+		t.Fatal("expected an invalid mnemonic to be rejected")
+	} else if _, err := DerivePrivKey(EthSecp256k1, testMnemonic, "", "m/44'/invalid"); err == nil {
+		t.Fatal("expected an invalid HD path to be rejected")
+	}
+}
+
+func TestDeriveAccounts(t *testing.T) {
+	accounts, err := DeriveAccounts(EthSecp256k1, testMnemonic, "", 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(accounts) != 3 {
+		t.Fatalf("expected 3 accounts, got %d", len(accounts))
+	}
+
+	seen := make(map[string]bool)
+	for idx, account := range accounts {
+		if expected := BIP44Path(EthCoinType, 0, uint32(idx)); account.HDPath != expected {
+			t.Fatalf("expected account %d at %s, got %s", idx, expected, account.HDPath)
+		}
+
+		privKey, err := DerivePrivKey(EthSecp256k1, testMnemonic, "", account.HDPath)
+		if err != nil {
+			t.Fatal(err)
+		} else if !privKey.Equals(account.PrivKey) || !account.Address.Equals(sdk.AccAddress(privKey.PubKey().Address())) {
+			t.Fatalf("expected account %d to have the key of its HD path", idx)
+		}
+
+		seen[account.Address.String()] = true
+	}
+
+	if len(seen) != 3 {
+		t.Fatal("expected the accounts to have different addresses")
+	}
+}
+
+func TestCoinTypeFor(t *testing.T) {
+	if coinType := CoinTypeFor(EthSecp256k1); coinType != 60 {
+		t.Fatalf("expected coin type 60 for eth_secp256k1, got %d", coinType)
+	} else if coinType := CoinTypeFor(hd.Secp256k1); coinType != 118 {
+		t.Fatalf("expected coin type 118 for secp256k1, got %d", coinType)
+	} else if path := BIP44Path(60, 1, 2); path != "m/44'/60'/1'/0/2" {
+		t.Fatalf("unexpected path %s", path)
+	}
+}
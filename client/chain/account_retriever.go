@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// accountRetriever works like authtypes.AccountRetriever, querying accounts by addresses
+// encoded with the chain config of the client instead of the global sdk config.
+// Accounts are queried via conn, e.g. an EndpointPool, or via the node of the client context when nil.
+type accountRetriever struct {
+	conn        grpc.ClientConnInterface
+	chainConfig common.ChainConfig
+}
+
+var _ client.AccountRetriever = accountRetriever{}
+
+func newAccountRetriever(conn grpc.ClientConnInterface, chainConfig common.ChainConfig) client.AccountRetriever {
+	return accountRetriever{
+		conn:        conn,
+		chainConfig: chainConfig,
+	}
+}
+
+func (ar accountRetriever) GetAccount(clientCtx client.Context, addr sdk.AccAddress) (client.Account, error) {
+	account, _, err := ar.GetAccountWithHeight(clientCtx, addr)
+	return account, err
+}
+
+func (ar accountRetriever) GetAccountWithHeight(clientCtx client.Context, addr sdk.AccAddress) (client.Account, int64, error) {
+	return ar.GetAccountWithHeightContext(context.Background(), clientCtx, addr)
+}
+
+// GetAccountWithHeightContext is GetAccountWithHeight honoring the ctx cancellation and deadline.
+func (ar accountRetriever) GetAccountWithHeightContext(ctx context.Context, clientCtx client.Context, addr sdk.AccAddress) (client.Account, int64, error) {
+	var header metadata.MD
+
+	var conn grpc.ClientConnInterface = clientCtx
+	if ar.conn != nil {
+		conn = ar.conn
+	}
+
+	queryClient := authtypes.NewQueryClient(conn)
+	res, err := queryClient.Account(ctx, &authtypes.QueryAccountRequest{
+		Address: ar.chainConfig.FormatAccAddress(addr),
+	}, grpc.Header(&header))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	blockHeight := header.Get(grpctypes.GRPCBlockHeightHeader)
+	if l := len(blockHeight); l != 1 {
+		return nil, 0, errors.Errorf("unexpected '%s' header length; got %d, expected: %d", grpctypes.GRPCBlockHeightHeader, l, 1)
+	}
+
+	height, err := strconv.ParseInt(blockHeight[0], 10, 64)
+	if err != nil {
+		err = errors.Wrap(err, "failed to parse block height")
+		return nil, 0, err
+	}
+
+	var acc authtypes.AccountI
+	if err := clientCtx.InterfaceRegistry.UnpackAny(res.Account, &acc); err != nil {
+		return nil, 0, err
+	}
+
+	return acc, height, nil
+}
+
+func (ar accountRetriever) EnsureExists(clientCtx client.Context, addr sdk.AccAddress) error {
+	_, err := ar.GetAccount(clientCtx, addr)
+	return err
+}
+
+func (ar accountRetriever) GetAccountNumberSequence(clientCtx client.Context, addr sdk.AccAddress) (uint64, uint64, error) {
+	return ar.GetAccountNumberSequenceContext(context.Background(), clientCtx, addr)
+}
+
+// GetAccountNumberSequenceContext is GetAccountNumberSequence honoring the ctx cancellation and deadline.
+func (ar accountRetriever) GetAccountNumberSequenceContext(ctx context.Context, clientCtx client.Context, addr sdk.AccAddress) (uint64, uint64, error) {
+	acc, _, err := ar.GetAccountWithHeightContext(ctx, clientCtx, addr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return acc.GetAccountNumber(), acc.GetSequence(), nil
+}
+
+// contextAccountRetriever is a client.AccountRetriever that honors the ctx of its queries.
+type contextAccountRetriever interface {
+	client.AccountRetriever
+
+	GetAccountNumberSequenceContext(ctx context.Context, clientCtx client.Context, addr sdk.AccAddress) (uint64, uint64, error)
+}
+
+// getAccountNumberSequence queries the account number and sequence of addr via ar,
+// passing ctx on when ar supports it. Missing accounts fail the query.
+func getAccountNumberSequence(ctx context.Context, ar client.AccountRetriever, clientCtx client.Context, addr sdk.AccAddress) (uint64, uint64, error) {
+	if ar, ok := ar.(contextAccountRetriever); ok {
+		return ar.GetAccountNumberSequenceContext(ctx, clientCtx, addr)
+	}
+
+	if err := ar.EnsureExists(clientCtx, addr); err != nil {
+		return 0, 0, err
+	}
+
+	return ar.GetAccountNumberSequence(clientCtx, addr)
+}
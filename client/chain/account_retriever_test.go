@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// fakeAccountConn answers account queries with an account of the queried address,
+// recording the address and replying with the height header unless noHeight is set.
+type fakeAccountConn struct {
+	queried  string
+	noHeight bool
+}
+
+func (c *fakeAccountConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	if method != "/cosmos.auth.v1beta1.Query/Account" {
+		return status.Error(codes.Unimplemented, method)
+	} else if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	c.queried = args.(*authtypes.QueryAccountRequest).Address
+	any, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{
+		Address:       c.queried,
+		AccountNumber: 7,
+		Sequence:      3,
+	})
+	if err != nil {
+		return err
+	}
+	reply.(*authtypes.QueryAccountResponse).Account = any
+
+	for _, opt := range opts {
+		if header, ok := opt.(grpc.HeaderCallOption); ok && !c.noHeight {
+			*header.HeaderAddr = metadata.Pairs(grpctypes.GRPCBlockHeightHeader, "42")
+		}
+	}
+
+	return nil
+}
+
+func (c *fakeAccountConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, method)
+}
+
+func TestAccountRetrieverUsesChainPrefix(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	clientCtx, err := NewClientContextWithConfig(chainConfig, "test-1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := &fakeAccountConn{}
+	retriever := newAccountRetriever(conn, chainConfig)
+	signer := newTestSigner()
+
+	acc, height, err := retriever.GetAccountWithHeight(clientCtx, signer.Address())
+	if err != nil {
+		t.Fatal(err)
+	} else if conn.queried != chainConfig.FormatAccAddress(signer.Address()) {
+		t.Fatalf("expected the address to be queried with the chain prefix, got %s", conn.queried)
+	} else if height != 42 || acc.GetAccountNumber() != 7 || acc.GetSequence() != 3 {
+		t.Fatalf("unexpected account %d/%d at height %d", acc.GetAccountNumber(), acc.GetSequence(), height)
+	}
+
+	if accNum, accSeq, err := retriever.GetAccountNumberSequence(clientCtx, signer.Address()); err != nil || accNum != 7 || accSeq != 3 {
+		t.Fatalf("unexpected account number and sequence %d/%d: %v", accNum, accSeq, err)
+	} else if err := retriever.EnsureExists(clientCtx, signer.Address()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAccountRetrieverRequiresHeightHeader(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	clientCtx, err := NewClientContextWithConfig(chainConfig, "test-1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	retriever := newAccountRetriever(&fakeAccountConn{noHeight: true}, chainConfig)
+	if _, _, err := retriever.GetAccountWithHeight(clientCtx, newTestSigner().Address()); err == nil {
+		t.Fatal("expected a response without the height header to be rejected")
+	}
+}
+
+func TestAccountRetrieverHonorsContext(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	clientCtx, err := NewClientContextWithConfig(chainConfig, "test-1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	retriever := newAccountRetriever(&fakeAccountConn{}, chainConfig)
+	if _, _, err := getAccountNumberSequence(ctx, retriever, clientCtx, newTestSigner().Address()); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected the canceled ctx to fail the query, got %v", err)
+	}
+}
+
+func TestPrepareFactoryHonorsContext(t *testing.T) {
+	a := startFakeNode(t, "aaa", newFakeChain())
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	ctx, cancelFn := context.WithCancel(context.Background())
+	cancelFn()
+
+	if _, err := c.prepareFactory(ctx, c.ctx, c.txFactory); status.Code(err) != codes.Canceled {
+		t.Fatalf("expected the canceled ctx to fail the account query, got %v", err)
+	}
+
+	txf, err := c.prepareFactory(context.Background(), c.ctx, c.txFactory.WithSequence(0).WithAccountNumber(0))
+	if err != nil {
+		t.Fatal(err)
+	} else if txf.AccountNumber() != c.accNum || txf.Sequence() != c.accSeq {
+		t.Fatalf("expected account %d/%d, got %d/%d", c.accNum, c.accSeq, txf.AccountNumber(), txf.Sequence())
+	}
+}
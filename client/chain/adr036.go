@@ -0,0 +1,108 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// adr036MsgType is the amino type of the ADR-036 MsgSignData
+const adr036MsgType = "sign/MsgSignData"
+
+// adr036SignDoc is the amino JSON sign doc of an ADR-036 arbitrary message, i.e. a StdSignDoc
+// with an empty chain ID, zero account number, sequence and fee and a single MsgSignData.
+type adr036SignDoc struct {
+	AccountNumber string      `json:"account_number"`
+	ChainID       string      `json:"chain_id"`
+	Fee           adr036Fee   `json:"fee"`
+	Memo          string      `json:"memo"`
+	Msgs          []adr036Msg `json:"msgs"`
+	Sequence      string      `json:"sequence"`
+}
+
+type adr036Fee struct {
+	Amount []sdk.Coin `json:"amount"`
+	Gas    string     `json:"gas"`
+}
+
+type adr036Msg struct {
+	Type  string            `json:"type"`
+	Value adr036MsgSignData `json:"value"`
+}
+
+type adr036MsgSignData struct {
+	// Data is encoded as base64, like amino JSON bytes
+	Data   []byte `json:"data"`
+	Signer string `json:"signer"`
+}
+
+// ADR036SignBytes returns the ADR-036 sign bytes of arbitrary data signed by the signer address,
+// as produced by wallets like Keplr for signArbitrary. The signer is encoded with the chain bech32 prefix.
+func ADR036SignBytes(chainConfig common.ChainConfig, signer sdk.AccAddress, data []byte) ([]byte, error) {
+	signDoc := adr036SignDoc{
+		AccountNumber: "0",
+		ChainID:       "",
+		Fee: adr036Fee{
+			Amount: []sdk.Coin{},
+			Gas:    "0",
+		},
+		Memo: "",
+		Msgs: []adr036Msg{{
+			Type: adr036MsgType,
+			Value: adr036MsgSignData{
+				Data:   data,
+				Signer: chainConfig.FormatAccAddress(signer),
+			},
+		}},
+		Sequence: "0",
+	}
+
+	bz, err := json.Marshal(signDoc)
+	if err != nil {
+		err = errors.Wrap(err, "failed to marshal ADR-036 sign doc")
+		return nil, err
+	}
+
+	return sdk.MustSortJSON(bz), nil
+}
+
+// SignADR036 signs arbitrary data off-chain with the signer, e.g. a login challenge,
+// following ADR-036. The signature can't be replayed as a Tx, since its sign doc has no chain ID.
+func SignADR036(ctx context.Context, chainConfig common.ChainConfig, signer Signer, data []byte) ([]byte, error) {
+	signBytes, err := ADR036SignBytes(chainConfig, signer.Address(), data)
+	if err != nil {
+		return nil, err
+	}
+
+	sigBytes, err := signer.Sign(ctx, signBytes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to sign ADR-036 data")
+		return nil, err
+	}
+
+	return sigBytes, nil
+}
+
+// VerifyADR036 verifies that the ADR-036 signature over data was created by the signer address
+// with the pubkey, which is either an ethsecp256k1 or a secp256k1 key.
+func VerifyADR036(chainConfig common.ChainConfig, pubKey cryptotypes.PubKey, signer sdk.AccAddress, data, sig []byte) error {
+	if !signer.Equals(sdk.AccAddress(pubKey.Address())) {
+		return errors.Errorf("pubkey doesn't match the signer address %s", chainConfig.FormatAccAddress(signer))
+	}
+
+	signBytes, err := ADR036SignBytes(chainConfig, signer, data)
+	if err != nil {
+		return err
+	}
+
+	if !pubKey.VerifySignature(signBytes, sig) {
+		return errors.Errorf("invalid ADR-036 signature of %s", chainConfig.FormatAccAddress(signer))
+	}
+
+	return nil
+}
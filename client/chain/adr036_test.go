@@ -0,0 +1,71 @@
+package chain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func TestADR036SignBytes(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	signer := sdk.AccAddress(make([]byte, 20))
+
+	signBytes, err := ADR036SignBytes(chainConfig, signer, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"account_number":"0","chain_id":"","fee":{"amount":[],"gas":"0"},"memo":"",` +
+		`"msgs":[{"type":"sign/MsgSignData","value":{"data":"aGVsbG8=","signer":"` + chainConfig.FormatAccAddress(signer) + `"}}],` +
+		`"sequence":"0"}`
+	if string(signBytes) != expected {
+		t.Fatalf("unexpected sign bytes %s", signBytes)
+	}
+}
+
+func TestSignADR036(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+
+	ethKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, signer := range []Signer{NewPrivKeySigner(secp256k1.GenPrivKey()), NewPrivKeySigner(ethKey)} {
+		sig, err := SignADR036(context.Background(), chainConfig, signer, []byte("challenge"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := VerifyADR036(chainConfig, signer.PubKey(), signer.Address(), []byte("challenge"), sig); err != nil {
+			t.Fatalf("expected a valid %s signature: %v", signer.PubKey().Type(), err)
+		} else if err := VerifyADR036(chainConfig, signer.PubKey(), signer.Address(), []byte("other"), sig); err == nil {
+			t.Fatalf("expected the %s signature of other data to be rejected", signer.PubKey().Type())
+		}
+
+		// a signature of another chain prefix covers another signer string
+		if err := VerifyADR036(common.InjectiveChainConfig(), signer.PubKey(), signer.Address(), []byte("challenge"), sig); err == nil {
+			t.Fatalf("expected the %s signature to be bound to the chain prefix", signer.PubKey().Type())
+		}
+	}
+}
+
+func TestVerifyADR036RejectsPubKeyOfAnotherSigner(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	signer := newTestSigner()
+	other := newTestSigner()
+
+	sig, err := SignADR036(context.Background(), chainConfig, signer, []byte("challenge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := VerifyADR036(chainConfig, other.PubKey(), signer.Address(), []byte("challenge"), sig); err == nil {
+		t.Fatal("expected a pubkey not matching the signer address to be rejected")
+	}
+}
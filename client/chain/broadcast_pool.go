@@ -0,0 +1,371 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/InjectiveLabs/suplog"
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
+	feegranttypes "github.com/cosmos/cosmos-sdk/x/feegrant"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// PoolStrategy defines how BroadcastPool picks the signer account for the next Tx.
+type PoolStrategy int
+
+const (
+	// PoolStrategyRoundRobin cycles through the signer accounts in order.
+	PoolStrategyRoundRobin PoolStrategy = iota
+	// PoolStrategyLeastLoaded picks the signer account with the fewest in-flight Txs.
+	PoolStrategyLeastLoaded
+)
+
+var ErrPoolEmpty = errors.New("broadcast pool has no signer accounts")
+
+// BroadcastPool broadcasts Txs concurrently from multiple signer accounts of the same keyring.
+// Every account keeps its own sequence, so Txs of different accounts don't block each other.
+type BroadcastPool interface {
+	Accounts() []sdk.AccAddress
+
+	SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	AsyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+
+	// BuildGrantMsgs builds the authz and feegrant messages the granter has to broadcast
+	// to allow every pool account to execute msgTypes and spend fees on its behalf.
+	BuildGrantMsgs(msgTypes []string, expireIn time.Time) []sdk.Msg
+}
+
+type BroadcastPoolOptions struct {
+	Strategy      PoolStrategy
+	AuthzGranter  sdk.AccAddress
+	FeeGranter    sdk.AccAddress
+	GrantSpendCap sdk.Coins
+}
+
+type BroadcastPoolOption func(opts *BroadcastPoolOptions) error
+
+func DefaultBroadcastPoolOptions() *BroadcastPoolOptions {
+	return &BroadcastPoolOptions{
+		Strategy: PoolStrategyRoundRobin,
+	}
+}
+
+func OptionPoolStrategy(strategy PoolStrategy) BroadcastPoolOption {
+	return func(opts *BroadcastPoolOptions) error {
+		switch strategy {
+		case PoolStrategyRoundRobin, PoolStrategyLeastLoaded:
+		default:
+			return errors.Errorf("unsupported pool strategy: %d", strategy)
+		}
+
+		opts.Strategy = strategy
+		return nil
+	}
+}
+
+// OptionPoolAuthzGranter makes the pool wrap all messages into authz MsgExec,
+// so they are executed on behalf of the granter account.
+func OptionPoolAuthzGranter(granter string) BroadcastPoolOption {
+	return func(opts *BroadcastPoolOptions) error {
+		addr, err := accAddressFromAnyBech32(granter)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to parse authz granter %s", granter)
+			return err
+		}
+
+		opts.AuthzGranter = addr
+		return nil
+	}
+}
+
+// OptionPoolFeeGranter makes the pool Txs pay fees from the granter account via feegrant.
+func OptionPoolFeeGranter(granter string) BroadcastPoolOption {
+	return func(opts *BroadcastPoolOptions) error {
+		addr, err := accAddressFromAnyBech32(granter)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to parse fee granter %s", granter)
+			return err
+		}
+
+		opts.FeeGranter = addr
+		return nil
+	}
+}
+
+// OptionPoolGrantSpendCap sets the spend limit of fee allowances built by BuildGrantMsgs.
+func OptionPoolGrantSpendCap(spendLimit string) BroadcastPoolOption {
+	return func(opts *BroadcastPoolOptions) error {
+		coins, err := sdk.ParseCoinsNormalized(spendLimit)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to ParseCoins %s", spendLimit)
+			return err
+		}
+
+		opts.GrantSpendCap = coins
+		return nil
+	}
+}
+
+type poolAccount struct {
+	ctx      client.Context
+	signer   Signer
+	mux      sync.Mutex
+	inFlight int64
+
+	accNum uint64
+	accSeq uint64
+}
+
+type broadcastPool struct {
+	client   *chainClient
+	opts     *BroadcastPoolOptions
+	logger   log.Logger
+	accounts []*poolAccount
+	next     uint64
+}
+
+// NewBroadcastPool creates a BroadcastPool for the keys referenced by fromSpecs. Each entry is either
+// a key name or a bech32 address of a key in the keyring of the provided chain client.
+func NewBroadcastPool(
+	cosmosClient ChainClient,
+	fromSpecs []string,
+	options ...BroadcastPoolOption,
+) (BroadcastPool, error) {
+	cc, ok := cosmosClient.(*chainClient)
+	if !ok {
+		return nil, errors.New("unsupported chain client implementation")
+	} else if !cc.canSign {
+		return nil, ErrReadOnly
+	} else if len(fromSpecs) == 0 {
+		return nil, ErrPoolEmpty
+	}
+
+	opts := DefaultBroadcastPoolOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in broadcast pool option")
+			return nil, err
+		}
+	}
+
+	pool := &broadcastPool{
+		client: cc,
+		opts:   opts,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "broadcastPool",
+		}),
+		accounts: make([]*poolAccount, 0, len(fromSpecs)),
+	}
+
+	for _, fromSpec := range fromSpecs {
+		keyInfo, err := keyInfoFromSpec(cc.opts.ChainConfig, cc.ctx.Keyring, fromSpec)
+		if err != nil {
+			return nil, err
+		}
+
+		accCtx := cc.ctx.
+			WithFromAddress(keyInfo.GetAddress()).
+			WithFromName(keyInfo.GetName()).
+			WithFrom(keyInfo.GetName())
+		if opts.FeeGranter != nil {
+			accCtx = accCtx.WithFeeGranterAddress(opts.FeeGranter)
+		}
+
+		signer, err := NewKeyringSigner(cc.ctx.Keyring, keyInfo.GetName())
+		if err != nil {
+			return nil, err
+		}
+
+		acc := &poolAccount{
+			ctx:    accCtx,
+			signer: signer,
+		}
+
+		acc.accNum, acc.accSeq, err = cc.txFactory.AccountRetriever().GetAccountNumberSequence(accCtx, keyInfo.GetAddress())
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get initial account num and seq of %s", cc.opts.ChainConfig.FormatAccAddress(keyInfo.GetAddress()))
+			return nil, err
+		}
+
+		pool.accounts = append(pool.accounts, acc)
+	}
+
+	return pool, nil
+}
+
+func keyInfoFromSpec(chainConfig common.ChainConfig, kb keyring.Keyring, fromSpec string) (keyring.Info, error) {
+	addr, err := chainConfig.ParseAccAddress(fromSpec)
+	if err == nil {
+		keyInfo, err := kb.KeyByAddress(addr)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to load key info by address %s", fromSpec)
+			return nil, err
+		}
+
+		return keyInfo, nil
+	}
+
+	keyInfo, err := kb.Key(fromSpec)
+	if err != nil {
+		err = errors.Wrapf(err, "no key in keyring for name: %s", fromSpec)
+		return nil, err
+	}
+
+	return keyInfo, nil
+}
+
+// accAddressFromAnyBech32 decodes a bech32 account address of any prefix, since pool options
+// are parsed before the chain config of the client is known.
+func accAddressFromAnyBech32(address string) (sdk.AccAddress, error) {
+	_, bz, err := bech32.DecodeAndConvert(address)
+	if err != nil {
+		return nil, err
+	} else if err := sdk.VerifyAddressFormat(bz); err != nil {
+		return nil, err
+	}
+
+	return sdk.AccAddress(bz), nil
+}
+
+func (p *broadcastPool) Accounts() []sdk.AccAddress {
+	addrs := make([]sdk.AccAddress, 0, len(p.accounts))
+	for _, acc := range p.accounts {
+		addrs = append(addrs, acc.ctx.GetFromAddress())
+	}
+
+	return addrs
+}
+
+// SyncBroadcastMsg sends Tx from the next pool account and waits until Tx is included in block.
+func (p *broadcastPool) SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
+	return p.broadcast(txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, msgs...)
+}
+
+// AsyncBroadcastMsg sends Tx from the next pool account and doesn't wait until Tx is included in block.
+func (p *broadcastPool) AsyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
+	return p.broadcast(txtypes.BroadcastMode_BROADCAST_MODE_SYNC, msgs...)
+}
+
+func (p *broadcastPool) BuildGrantMsgs(msgTypes []string, expireIn time.Time) []sdk.Msg {
+	grantMsgs := make([]sdk.Msg, 0, len(p.accounts)*(len(msgTypes)+1))
+	chainConfig := p.client.opts.ChainConfig
+
+	for _, acc := range p.accounts {
+		grantee := acc.ctx.GetFromAddress()
+
+		if p.opts.AuthzGranter != nil {
+			for _, msgType := range msgTypes {
+				grantMsgs = append(grantMsgs, p.client.BuildGenericAuthz(
+					chainConfig.FormatAccAddress(p.opts.AuthzGranter),
+					chainConfig.FormatAccAddress(grantee),
+					msgType,
+					expireIn,
+				))
+			}
+		}
+
+		if p.opts.FeeGranter != nil {
+			allowance := &feegranttypes.BasicAllowance{
+				SpendLimit: p.opts.GrantSpendCap,
+				Expiration: &expireIn,
+			}
+
+			msg, err := feegranttypes.NewMsgGrantAllowance(allowance, p.opts.FeeGranter, grantee)
+			if err != nil {
+				p.logger.WithError(err).Errorln("failed to build fee allowance for", chainConfig.FormatAccAddress(grantee))
+				continue
+			}
+			msg.Granter = chainConfig.FormatAccAddress(p.opts.FeeGranter)
+			msg.Grantee = chainConfig.FormatAccAddress(grantee)
+
+			grantMsgs = append(grantMsgs, msg)
+		}
+	}
+
+	return grantMsgs
+}
+
+func (p *broadcastPool) pickAccount() *poolAccount {
+	if p.opts.Strategy == PoolStrategyLeastLoaded {
+		var picked *poolAccount
+		var pickedLoad int64
+		for _, acc := range p.accounts {
+			load := atomic.LoadInt64(&acc.inFlight)
+			if picked == nil || load < pickedLoad {
+				picked = acc
+				pickedLoad = load
+			}
+		}
+
+		return picked
+	}
+
+	idx := atomic.AddUint64(&p.next, 1) - 1
+	return p.accounts[idx%uint64(len(p.accounts))]
+}
+
+func (p *broadcastPool) broadcast(mode txtypes.BroadcastMode, msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
+	acc := p.pickAccount()
+
+	atomic.AddInt64(&acc.inFlight, 1)
+	defer atomic.AddInt64(&acc.inFlight, -1)
+
+	if p.opts.AuthzGranter != nil {
+		msgExec := authztypes.NewMsgExec(acc.ctx.GetFromAddress(), msgs)
+		msgExec.Grantee = p.client.opts.ChainConfig.FormatAccAddress(acc.ctx.GetFromAddress())
+		msgs = []sdk.Msg{&msgExec}
+	}
+
+	acc.mux.Lock()
+	defer acc.mux.Unlock()
+
+	// the factory is built per Tx from the client one, the timeout height is set by broadcastTx
+	txf := p.client.txFactory.WithSequence(acc.accSeq).WithAccountNumber(acc.accNum)
+	res, err := p.client.broadcastTx(context.Background(), acc.ctx, txf, acc.signer, mode, msgs...)
+	if isSequenceMismatch(res, err) {
+		p.syncNonce(acc)
+		txf = p.client.txFactory.WithSequence(acc.accSeq).WithAccountNumber(acc.accNum)
+		log.Debugln("retrying broadcastTx with nonce", acc.accSeq)
+		res, err = p.client.broadcastTx(context.Background(), acc.ctx, txf, acc.signer, mode, msgs...)
+	}
+	if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		p.logger.WithFields(log.Fields{
+			"size":    len(msgs),
+			"account": p.client.opts.ChainConfig.FormatAccAddress(acc.ctx.GetFromAddress()),
+		}).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		return nil, err
+	}
+
+	if isSequenceConsumed(res.TxResponse) {
+		acc.accSeq++
+	}
+
+	return res, ParseTxError(res.TxResponse)
+}
+
+func (p *broadcastPool) syncNonce(acc *poolAccount) {
+	num, seq, err := p.client.txFactory.AccountRetriever().GetAccountNumberSequence(acc.ctx, acc.ctx.GetFromAddress())
+	if err != nil {
+		p.logger.WithError(err).Errorln("failed to get account seq")
+		return
+	} else if num != acc.accNum {
+		p.logger.WithFields(log.Fields{
+			"expected": acc.accNum,
+			"actual":   num,
+		}).Panic("account number changed during nonce sync")
+	}
+
+	acc.accSeq = seq
+}
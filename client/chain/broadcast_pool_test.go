@@ -0,0 +1,106 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// newTestBroadcastPool returns a pool of new keys, created with a client signing with the first one.
+func newTestBroadcastPool(t *testing.T, nodes []*fakeNode, accounts int, options ...common.ClientOption) BroadcastPool {
+	t.Helper()
+
+	kb := keyring.NewInMemory()
+	names := make([]string, 0, accounts)
+	for i := 0; i < accounts; i++ {
+		info, _, err := kb.NewMnemonic(string(rune('a'+i)), keyring.English, sdk.FullFundraiserPath, "", hd.Secp256k1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, info.GetName())
+	}
+
+	clientCtx, err := NewClientContext("test-1", names[0], kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestChainClientWithContext(t, clientCtx, nil, nodes, options...)
+	pool, err := NewBroadcastPool(c, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pool
+}
+
+func newTestPoolMsgSend(from sdk.AccAddress) sdk.Msg {
+	return &banktypes.MsgSend{
+		FromAddress: from.String(),
+		ToAddress:   from.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("aaa", 1)),
+	}
+}
+
+func TestBroadcastPoolTimeoutHeightFollowsLatestBlock(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	pool := newTestBroadcastPool(t, []*fakeNode{a}, 2, common.OptionTimeoutHeight(20))
+	accounts := pool.Accounts()
+
+	for round := 0; round < 3; round++ {
+		for _, acc := range accounts {
+			height := chain.latestHeight()
+			awaitCachedHeight(t, pool.(*broadcastPool).client, height)
+			if _, err := pool.AsyncBroadcastMsg(newTestPoolMsgSend(acc)); err != nil {
+				t.Fatalf("round %d: %v", round, err)
+			}
+
+			broadcasts := chain.sentBroadcasts()
+			if got := broadcasts[len(broadcasts)-1].timeoutHeight; got != uint64(height)+20 {
+				t.Fatalf("round %d: expected timeout height %d, got %d", round, height+20, got)
+			}
+		}
+
+		// pool Txs are sent long after the pool was created
+		for j := 0; j < 50; j++ {
+			chain.commit()
+		}
+	}
+}
+
+func TestBroadcastPoolRoundRobin(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	pool := newTestBroadcastPool(t, []*fakeNode{a}, 3)
+	accounts := pool.Accounts()
+
+	for i := 0; i < 6; i++ {
+		res, err := pool.SyncBroadcastMsg(newTestPoolMsgSend(accounts[i%3]))
+		if err != nil {
+			t.Fatal(err)
+		} else if res.TxResponse.Height == 0 {
+			t.Fatalf("expected the tx to be included, got %+v", res.TxResponse)
+		}
+	}
+
+	broadcasts := chain.sentBroadcasts()
+	for i, broadcast := range broadcasts {
+		if broadcast.code != 0 {
+			t.Fatalf("broadcast %d was rejected with code %d", i, broadcast.code)
+		} else if want := broadcasts[i%3].account; broadcast.account != want {
+			t.Errorf("broadcast %d was not sent by account %d", i, i%3)
+		} else if broadcast.sequence != uint64(i/3) {
+			t.Errorf("broadcast %d: expected sequence %d, got %d", i, i/3, broadcast.sequence)
+		}
+	}
+}
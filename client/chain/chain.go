@@ -2,15 +2,9 @@ package chain
 
 import (
 	"context"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"math"
 	"math/big"
-	"net/http"
-	"os"
-	"strconv"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,11 +12,13 @@ import (
 	wasmtypes "github.com/CosmWasm/wasmd/x/wasm/types"
 	log "github.com/InjectiveLabs/suplog"
 	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	cosmtypes "github.com/cosmos/cosmos-sdk/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	authztypes "github.com/cosmos/cosmos-sdk/x/authz"
 	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
@@ -30,39 +26,44 @@ import (
 	"github.com/gotabit/sdk-go/client/common"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	msgCommitBatchSizeLimit          = 1024
-	msgCommitBatchTimeLimit          = 500 * time.Millisecond
-	defaultBroadcastStatusPoll       = 100 * time.Millisecond
-	defaultBroadcastTimeout          = 40 * time.Second
-	defaultTimeoutHeight             = 20
-	defaultTimeoutHeightSyncInterval = 10 * time.Second
-	defaultSessionRenewalOffset      = 120
-	defaultBlockTime                 = 3 * time.Second
-	defaultChainCookieName           = ".chain_cookie"
+	defaultLatestHeightTimeout = 5 * time.Second
 )
 
 var (
 	ErrTimedOut       = errors.New("tx timed out")
 	ErrQueueClosed    = errors.New("queue is closed")
+	ErrTxInFlight     = errors.New("tx is in flight, its outcome is unknown")
 	ErrEnqueueTimeout = errors.New("enqueue timeout")
 	ErrReadOnly       = errors.New("client is in read-only mode")
+	ErrOffline        = errors.New("client is offline")
 )
 
 type ChainClient interface {
 	CanSignTransactions() bool
 	FromAddress() sdk.AccAddress
+	ChainConfig() common.ChainConfig
 	QueryClient() *grpc.ClientConn
 	ClientContext() client.Context
 
 	SimulateMsg(clientCtx client.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error)
 	AsyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
 	SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	SimulateMsgs(ctx context.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error)
+	BroadcastMsgs(ctx context.Context, mode txtypes.BroadcastMode, msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	BroadcastMsgsWithOverrides(ctx context.Context, mode txtypes.BroadcastMode, overrides TxOverrides, msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error)
+	BuildUnsignedTx(ctx context.Context, overrides TxOverrides, msgs ...sdk.Msg) (*OfflineTx, error)
+	BroadcastSignedTx(ctx context.Context, txBytes []byte, mode txtypes.BroadcastMode) (*txtypes.BroadcastTxResponse, error)
 	QueueBroadcastMsg(msgs ...sdk.Msg) error
+	QueueBroadcastMsgWithResult(msgs ...sdk.Msg) ([]*MsgFuture, error)
+	PipelineBroadcastMsg(msgs ...sdk.Msg) ([]*MsgFuture, error)
 
 	GetBankBalances(ctx context.Context, address string) (*banktypes.QueryAllBalancesResponse, error)
 	GetBankBalance(ctx context.Context, address string, denom string) (*banktypes.QueryBalanceResponse, error)
@@ -84,7 +85,10 @@ type ChainClient interface {
 		queryData []byte,
 	) (*wasmtypes.QueryRawContractStateResponse, error)
 
-	GetGasFee() (string, error)
+	EstimateFee(ctx context.Context, gas uint64) (sdk.Coins, error)
+	// GetGasFee returns the fee paid by the last Tx. It used to return an amount of inj
+	// as a decimal string, callers formatting it should use sdk.Coins.String().
+	GetGasFee() (sdk.Coins, error)
 	Close()
 }
 
@@ -92,38 +96,109 @@ type chainClient struct {
 	ctx       client.Context
 	opts      *common.ClientOptions
 	logger    log.Logger
-	conn      *grpc.ClientConn
+	endpoints *EndpointPool
+	// txFactory holds the client-wide Tx settings, it isn't modified after the client is
+	// created. Every Tx derives its own factory from it, with its sequence and timeout height.
 	txFactory tx.Factory
 
 	fromAddress sdk.AccAddress
 	doneC       chan bool
-	msgC        chan sdk.Msg
-	syncMux     *sync.Mutex
-
-	accNum    uint64
-	accSeq    uint64
-	gasWanted uint64
-	gasFee    string
-
-	sessionCookie  string
-	sessionEnabled bool
+	msgC        chan queuedMsg
+	syncMux     syncLock
+	pipeline    *txPipeline
+	txSubs      *txSubscriptions
+
+	accNum      uint64
+	accSeq      uint64
+	gasWanted   uint64
+	maxGasPerTx uint64
+	// lastHeight is the last known block height, Tx timeout heights fall back to it
+	lastHeight int64
+
+	fees      *feeEstimator
+	feeMux    sync.RWMutex
+	lastTxFee sdk.Coins
 
 	txClient         txtypes.ServiceClient
+	tmQueryClient    tmservice.ServiceClient
 	authQueryClient  authtypes.QueryClient
 	bankQueryClient  banktypes.QueryClient
 	authzQueryClient authztypes.QueryClient
 	wasmQueryClient  wasmtypes.QueryClient
 
-	closed  int64
-	canSign bool
+	closed    int64
+	closeC    chan struct{}
+	closeOnce sync.Once
+	signer    Signer
+	canSign   bool
 }
 
 // NewCosmosClient creates a new gRPC client that communicates with gRPC server at protoAddr.
 // protoAddr must be in form "tcp://127.0.0.1:8080" or "unix:///tmp/test.sock", protocol is required.
+// Txs are signed with the from key of the keyring in ctx, when it can sign.
 func NewChainClient(
 	ctx client.Context,
 	protoAddr string,
 	options ...common.ClientOption,
+) (ChainClient, error) {
+	var signer Signer
+	if canSignWith(ctx) {
+		var err error
+		if signer, err = NewKeyringSigner(ctx.Keyring, ctx.GetFromName()); err != nil {
+			return nil, err
+		}
+	}
+
+	return newChainClient(ctx, signer, protoAddr, nil, options...)
+}
+
+// NewChainClientWithSigner creates a new gRPC client like NewChainClient, with Txs signed
+// by the signer instead of a keyring. The from address of ctx is set to the signer address.
+func NewChainClientWithSigner(
+	ctx client.Context,
+	signer Signer,
+	protoAddr string,
+	options ...common.ClientOption,
+) (ChainClient, error) {
+	if signer == nil {
+		return nil, errors.New("signer is required")
+	}
+
+	ctx = ctx.WithFromAddress(signer.Address())
+	return newChainClient(ctx, signer, protoAddr, nil, options...)
+}
+
+// NewChainClientWithEndpointPool creates a new gRPC client like NewChainClient, with calls routed
+// to the healthiest node of the pool. Txs are signed by the signer, or with the from key of the
+// keyring in ctx when the signer is nil. The pool is closed with the client.
+func NewChainClientWithEndpointPool(
+	ctx client.Context,
+	signer Signer,
+	pool *EndpointPool,
+	options ...common.ClientOption,
+) (ChainClient, error) {
+	if pool == nil {
+		return nil, ErrNoEndpoints
+	}
+
+	if signer != nil {
+		ctx = ctx.WithFromAddress(signer.Address())
+	} else if canSignWith(ctx) {
+		var err error
+		if signer, err = NewKeyringSigner(ctx.Keyring, ctx.GetFromName()); err != nil {
+			return nil, err
+		}
+	}
+
+	return newChainClient(ctx, signer, "", pool, options...)
+}
+
+func newChainClient(
+	ctx client.Context,
+	signer Signer,
+	protoAddr string,
+	pool *EndpointPool,
+	options ...common.ClientOption,
 ) (ChainClient, error) {
 	// process options
 	opts := common.DefaultClientOptions()
@@ -134,22 +209,43 @@ func NewChainClient(
 		}
 	}
 
-	txFactory := NewTxFactory(ctx)
-	if len(opts.GasPrices) > 0 {
-		txFactory = txFactory.WithGasPrices(opts.GasPrices)
+	if opts.BroadcastStatusPoll >= opts.BroadcastTimeout {
+		err := errors.Errorf("broadcast status poll %s must be shorter than broadcast timeout %s", opts.BroadcastStatusPoll, opts.BroadcastTimeout)
+		return nil, err
 	}
 
-	var conn *grpc.ClientConn
-	var err error
-	stickySessionEnabled := true
-	if opts.TLSCert != nil {
-		conn, err = grpc.Dial(protoAddr, grpc.WithTransportCredentials(opts.TLSCert), grpc.WithContextDialer(common.DialerFunc))
-	} else {
-		conn, err = grpc.Dial(protoAddr, grpc.WithInsecure(), grpc.WithContextDialer(common.DialerFunc))
-		stickySessionEnabled = false
+	// pools created here are closed again when the client can't be created
+	ownPool := pool == nil && !opts.Offline
+	if ownPool {
+		var err error
+		pool, err = NewEndpointPool([]PoolNode{{
+			Address: protoAddr,
+			TLSCert: opts.TLSCert,
+		}}, OptionEndpointSessionDir(opts.SessionDir), OptionEndpointRetryPolicy(opts.RetryPolicy))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// offline clients only build Txs, their queries fail right away
+	var conn grpc.ClientConnInterface = pool
+	if opts.Offline {
+		conn = offlineConn{}
+		signer = nil
+		ctx = ctx.WithOffline(true).WithGenerateOnly(true).WithSimulation(false)
 	}
+
+	// accounts are queried via the pool, by addresses with the prefix of the client chain
+	ctx = ctx.WithAccountRetriever(newAccountRetriever(conn, opts.ChainConfig))
+
+	// gas prices are applied by the fee estimator
+	txFactory := NewTxFactory(ctx).WithGasAdjustment(opts.GasAdjustment)
+
+	fees, err := newFeeEstimator(conn, opts)
 	if err != nil {
-		err := errors.Wrapf(err, "failed to connect to the gRPC: %s", protoAddr)
+		if ownPool {
+			pool.Close()
+		}
 		return nil, err
 	}
 
@@ -163,16 +259,19 @@ func NewChainClient(
 			"svc":    "chainClient",
 		}),
 
-		conn:      conn,
+		endpoints: pool,
 		txFactory: txFactory,
-		canSign:   ctx.Keyring != nil,
-		syncMux:   new(sync.Mutex),
-		msgC:      make(chan sdk.Msg, msgCommitBatchSizeLimit),
+		fees:      fees,
+		signer:    signer,
+		canSign:   signer != nil,
+		txSubs:    newTxSubscriptions(ctx.Client),
+		syncMux:   newSyncLock(),
+		msgC:      make(chan queuedMsg, opts.BatchSizeLimit),
 		doneC:     make(chan bool, 1),
-
-		sessionEnabled: stickySessionEnabled,
+		closeC:    make(chan struct{}),
 
 		txClient:         txtypes.NewServiceClient(conn),
+		tmQueryClient:    tmservice.NewServiceClient(conn),
 		authQueryClient:  authtypes.NewQueryClient(conn),
 		bankQueryClient:  banktypes.NewQueryClient(conn),
 		authzQueryClient: authztypes.NewQueryClient(conn),
@@ -184,24 +283,23 @@ func NewChainClient(
 
 		cc.accNum, cc.accSeq, err = cc.txFactory.AccountRetriever().GetAccountNumberSequence(ctx, ctx.GetFromAddress())
 		if err != nil {
+			cc.txSubs.close()
+			if ownPool {
+				pool.Close()
+			}
 			err = errors.Wrap(err, "failed to get initial account num and seq")
 			return nil, err
 		}
 
-		go cc.runBatchBroadcast()
-		go cc.syncTimeoutHeight()
-	}
+		cc.maxGasPerTx = cc.resolveMaxGasPerTx()
+		cc.pipeline = newTxPipeline(cc)
 
-	// create file if not exist
-	os.OpenFile(defaultChainCookieName, os.O_RDONLY|os.O_CREATE, 0666)
+		go cc.runBatchBroadcast()
+		go cc.pipeline.run()
 
-	// attempt to load from disk
-	data, err := os.ReadFile(defaultChainCookieName)
-	if err != nil {
-		cc.logger.Errorln(err)
-	} else {
-		cc.sessionCookie = string(data)
-		cc.logger.Infoln("chain session cookie loaded from disk")
+		if opts.TimeoutHeight > 0 {
+			go cc.syncTimeoutHeight()
+		}
 	}
 
 	return cc, nil
@@ -222,44 +320,91 @@ func (c *chainClient) syncNonce() {
 	c.accSeq = seq
 }
 
+// syncTimeoutHeight keeps the last known block height fresh, for broadcasts that fail to query it.
+// Failed queries are retried with the backoff of the retry policy, until the client is closed.
 func (c *chainClient) syncTimeoutHeight() {
-	for {
-		ctx := context.Background()
-		block, err := c.ctx.Client.Block(ctx, nil)
+	for attempt := 0; ; {
+		_, err := c.latestHeight(context.Background())
+
+		wait := c.opts.TimeoutHeightSyncInterval
 		if err != nil {
-			c.logger.WithError(err).Errorln("failed to get current block")
+			attempt++
+			if backoff := c.opts.RetryPolicy.Backoff(attempt); backoff < wait {
+				wait = backoff
+			}
+			c.logger.WithError(err).Warningln("failed to get latest block height, retrying in", wait)
+		} else {
+			attempt = 0
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-c.closeC:
+			t.Stop()
 			return
+		case <-t.C:
+		}
+	}
+}
+
+// latestHeight queries the latest block height via the endpoint pool and remembers it.
+func (c *chainClient) latestHeight(ctx context.Context) (int64, error) {
+	ctx, cancelFn := context.WithTimeout(ctx, defaultLatestHeightTimeout)
+	defer cancelFn()
+
+	res, err := c.tmQueryClient.GetLatestBlock(ctx, &tmservice.GetLatestBlockRequest{})
+	if err != nil {
+		err = errors.Wrap(err, "failed to GetLatestBlock")
+		return 0, err
+	}
+
+	height := res.GetBlock().GetHeader().Height
+	atomic.StoreInt64(&c.lastHeight, height)
+
+	return height, nil
+}
+
+// withTimeoutHeight sets the Tx timeout height the configured number of blocks after the last
+// known block height, kept fresh by syncTimeoutHeight. The latest block is queried only when
+// no height is known yet, and Txs get no timeout height when that query fails as well.
+// Timeout heights overridden for the Tx are kept.
+func (c *chainClient) withTimeoutHeight(ctx context.Context, txf tx.Factory) tx.Factory {
+	if c.opts.TimeoutHeight == 0 || txf.TimeoutHeight() > 0 {
+		return txf
+	}
+
+	height := atomic.LoadInt64(&c.lastHeight)
+	if height == 0 {
+		var err error
+		if height, err = c.latestHeight(ctx); err != nil {
+			c.logger.WithError(err).Warningln("no block height known, sending tx without timeout height")
+			return txf
 		}
-		c.txFactory.WithTimeoutHeight(uint64(block.Block.Height) + defaultTimeoutHeight)
-		time.Sleep(defaultTimeoutHeightSyncInterval)
 	}
+
+	if height == 0 {
+		return txf
+	}
+
+	return txf.WithTimeoutHeight(uint64(height) + c.opts.TimeoutHeight)
 }
 
 // prepareFactory ensures the account defined by ctx.GetFromAddress() exists and
 // if the account number and/or the account sequence number are zero (not set),
 // they will be queried for and set on the provided Factory. A new Factory with
-// the updated fields will be returned.
-func (c *chainClient) prepareFactory(clientCtx client.Context, txf tx.Factory) (tx.Factory, error) {
-	from := clientCtx.GetFromAddress()
-
-	if err := txf.AccountRetriever().EnsureExists(clientCtx, from); err != nil {
+// the updated fields will be returned. The account query honors ctx.
+func (c *chainClient) prepareFactory(ctx context.Context, clientCtx client.Context, txf tx.Factory) (tx.Factory, error) {
+	num, seq, err := getAccountNumberSequence(ctx, txf.AccountRetriever(), clientCtx, clientCtx.GetFromAddress())
+	if err != nil {
 		return txf, err
 	}
 
-	initNum, initSeq := txf.AccountNumber(), txf.Sequence()
-	if initNum == 0 || initSeq == 0 {
-		num, seq, err := txf.AccountRetriever().GetAccountNumberSequence(clientCtx, from)
-		if err != nil {
-			return txf, err
-		}
-
-		if initNum == 0 {
-			txf = txf.WithAccountNumber(num)
-		}
+	if txf.AccountNumber() == 0 {
+		txf = txf.WithAccountNumber(num)
+	}
 
-		if initSeq == 0 {
-			txf = txf.WithSequence(seq)
-		}
+	if txf.Sequence() == 0 {
+		txf = txf.WithSequence(seq)
 	}
 
 	return txf, nil
@@ -272,70 +417,13 @@ func (c *chainClient) getAccSeq() uint64 {
 	return c.accSeq
 }
 
-func (c *chainClient) setCookie(metadata metadata.MD) {
-	if !c.sessionEnabled {
-		return
-	}
-	md := metadata.Get("set-cookie")
-	if len(md) > 0 {
-		// write to client instance
-		c.sessionCookie = md[0]
-		// write to disk
-		err := os.WriteFile(defaultChainCookieName, []byte(md[0]), 0644)
-		if err != nil {
-			c.logger.Errorln(err)
-			return
-		}
-		c.logger.Infoln("chain session cookie saved to disk")
-	}
-}
-
-func (c *chainClient) fetchCookie(ctx context.Context) context.Context {
-	var header metadata.MD
-	c.txClient.GetTx(context.Background(), &txtypes.GetTxRequest{}, grpc.Header(&header))
-	c.setCookie(header)
-	time.Sleep(defaultBlockTime)
-	return metadata.NewOutgoingContext(ctx, metadata.Pairs("cookie", c.sessionCookie))
-}
-
-func (c *chainClient) getCookie(ctx context.Context) context.Context {
-	md := metadata.Pairs("cookie", c.sessionCookie)
-	if !c.sessionEnabled {
-		return metadata.NewOutgoingContext(ctx, md)
-	}
-
-	// borrow http request to parse cookie
-	header := http.Header{}
-	header.Add("Cookie", c.sessionCookie)
-	request := http.Request{Header: header}
-	cookies := request.Cookies()
-
-	if len(cookies) > 0 {
-		// format cookie date into RFC1123 standard
-		expiresAt := strings.Replace(cookies[1].Value, "-", " ", -1)
-		yyyy := fmt.Sprintf("20%s", expiresAt[12:14])
-		expiresAt = expiresAt[:12] + yyyy + expiresAt[14:]
-
-		// parse expire field into unix timestamp
-		expiresTimestamp, err := time.Parse(time.RFC1123, expiresAt)
-		if err != nil {
-			panic(err)
-		}
-
-		// renew session if timestamp diff < offset
-		timestampDiff := expiresTimestamp.Unix() - time.Now().Unix()
-		if timestampDiff < defaultSessionRenewalOffset {
-			return c.fetchCookie(ctx)
-		}
-	} else {
-		return c.fetchCookie(ctx)
+// QueryClient returns the connection of the healthiest endpoint, or nil for offline clients.
+func (c *chainClient) QueryClient() *grpc.ClientConn {
+	if c.endpoints == nil {
+		return nil
 	}
 
-	return metadata.NewOutgoingContext(ctx, md)
-}
-
-func (c *chainClient) QueryClient() *grpc.ClientConn {
-	return c.conn
+	return c.endpoints.Conn()
 }
 
 func (c *chainClient) ClientContext() client.Context {
@@ -346,25 +434,36 @@ func (c *chainClient) CanSignTransactions() bool {
 	return c.canSign
 }
 
+// FromAddress returns the address of the from key, which is also set for watch-only keys
+// that can't sign, like offline and multisig keys.
 func (c *chainClient) FromAddress() sdk.AccAddress {
-	if !c.canSign {
-		return sdk.AccAddress{}
-	}
-
 	return c.ctx.FromAddress
 }
 
+// ChainConfig returns the bech32 prefixes and keys settings the client encodes addresses with,
+// e.g. c.ChainConfig().FormatAccAddress(c.FromAddress()).
+func (c *chainClient) ChainConfig() common.ChainConfig {
+	return c.opts.ChainConfig
+}
+
+// Close commits the queued msgs of clients that can sign, then closes the tx subscriptions
+// and the endpoint pool, if any. It's safe to call more than once.
 func (c *chainClient) Close() {
-	if !c.canSign {
-		return
-	}
-	if atomic.CompareAndSwapInt64(&c.closed, 0, 1) {
-		close(c.msgC)
-	}
-	<-c.doneC
-	if c.conn != nil {
-		c.conn.Close()
-	}
+	c.closeOnce.Do(func() {
+		atomic.StoreInt64(&c.closed, 1)
+		close(c.closeC)
+
+		if c.canSign {
+			close(c.msgC)
+			<-c.doneC
+			c.pipeline.close()
+		}
+
+		c.txSubs.close()
+		if c.endpoints != nil {
+			c.endpoints.Close()
+		}
+	})
 }
 
 func (c *chainClient) GetBankBalances(ctx context.Context, address string) (*banktypes.QueryAllBalancesResponse, error) {
@@ -390,55 +489,42 @@ func (c *chainClient) GetBankBalance(ctx context.Context, address string, denom
 }
 
 // SyncBroadcastMsg sends Tx to chain and waits until Tx is included in block.
+// If the Tx fails, the response is returned along with the typed error of the failure.
 func (c *chainClient) SyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
-	c.syncMux.Lock()
-	defer c.syncMux.Unlock()
-
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
-
-	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-			res, err = c.broadcastTx(c.ctx, c.txFactory, true, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-			return nil, err
-		}
-	}
+	return c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, msgs...)
+}
 
-	c.accSeq++
+func (c *chainClient) SimulateMsg(clientCtx client.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error) {
+	return c.simulateMsgs(context.Background(), clientCtx, msgs...)
+}
 
-	return res, nil
+// SimulateMsgs simulates msgs as a single Tx of the client account, the ctx cancellation is honored.
+func (c *chainClient) SimulateMsgs(ctx context.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error) {
+	return c.simulateMsgs(ctx, c.ctx, msgs...)
 }
 
-func (c *chainClient) SimulateMsg(clientCtx client.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error) {
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	txf, err := c.prepareFactory(clientCtx, c.txFactory)
+func (c *chainClient) simulateMsgs(ctx context.Context, clientCtx client.Context, msgs ...sdk.Msg) (*txtypes.SimulateResponse, error) {
+	if err := c.syncMux.LockContext(ctx); err != nil {
+		return nil, err
+	}
+	txf := c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+	c.syncMux.Unlock()
+
+	txf, err := c.prepareFactory(ctx, clientCtx, txf)
 	if err != nil {
 		err = errors.Wrap(err, "failed to prepareFactory")
 		return nil, err
 	}
 
-	simTxBytes, err := tx.BuildSimTx(txf, msgs...)
+	simTxBytes, err := buildSimTx(clientCtx.TxConfig, txf, c.fromPubKey(), msgs)
 	if err != nil {
 		err = errors.Wrap(err, "failed to build sim tx bytes")
 		return nil, err
 	}
 
-	ctx := context.Background()
-	ctx = c.getCookie(ctx)
-	var header metadata.MD
-	simRes, err := c.txClient.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: simTxBytes}, grpc.Header(&header))
+	simRes, err := c.txClient.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: simTxBytes})
 	if err != nil {
-		err = errors.Wrap(err, "failed to CalculateGas")
+		err = errors.Wrap(ParseGRPCError(err), "failed to CalculateGas")
 		return nil, err
 	}
 
@@ -448,132 +534,308 @@ func (c *chainClient) SimulateMsg(clientCtx client.Context, msgs ...sdk.Msg) (*t
 // AsyncBroadcastMsg sends Tx to chain and doesn't wait until Tx is included in block. This method
 // cannot be used for rapid Tx sending, it is expected that you wait for transaction status with
 // external tools. If you want sdk to wait for it, use SyncBroadcastMsg.
+// If the Tx is rejected, the response is returned along with the typed error of the failure.
 func (c *chainClient) AsyncBroadcastMsg(msgs ...sdk.Msg) (*txtypes.BroadcastTxResponse, error) {
-	c.syncMux.Lock()
+	return c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, msgs...)
+}
+
+// BroadcastMsgs sends Tx to chain using the broadcast mode. BROADCAST_MODE_BLOCK waits until Tx
+// is included in block. The ctx cancellation is honored during simulation, signing, broadcasting
+// and waiting for the Tx inclusion.
+func (c *chainClient) BroadcastMsgs(
+	ctx context.Context,
+	mode txtypes.BroadcastMode,
+	msgs ...sdk.Msg,
+) (*txtypes.BroadcastTxResponse, error) {
+	return c.BroadcastMsgsWithOverrides(ctx, mode, TxOverrides{}, msgs...)
+}
+
+// BroadcastMsgsWithOverrides works like BroadcastMsgs, with the Tx parameters overridden for this call only.
+func (c *chainClient) BroadcastMsgsWithOverrides(
+	ctx context.Context,
+	mode txtypes.BroadcastMode,
+	overrides TxOverrides,
+	msgs ...sdk.Msg,
+) (*txtypes.BroadcastTxResponse, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
+	}
+
+	// another Tx of the account may take long to broadcast, don't wait beyond ctx for it
+	if err := c.syncMux.LockContext(ctx); err != nil {
+		return nil, err
+	}
 	defer c.syncMux.Unlock()
 
-	c.txFactory = c.txFactory.WithSequence(c.accSeq)
-	c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-	res, err := c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
+	clientCtx, txf, err := overrides.apply(c.ctx, c.txFactory)
 	if err != nil {
-		if strings.Contains(err.Error(), "account sequence mismatch") {
-			c.syncNonce()
-			c.txFactory = c.txFactory.WithSequence(c.accSeq)
-			c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-			log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-			res, err = c.broadcastTx(c.ctx, c.txFactory, false, msgs...)
-		}
-		if err != nil {
-			resJSON, _ := json.MarshalIndent(res, "", "\t")
-			c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-			return nil, err
-		}
+		return nil, err
 	}
 
-	c.accSeq++
+	txf = txf.WithSequence(c.accSeq)
+	txf = txf.WithAccountNumber(c.accNum)
+	res, err := c.broadcastTx(ctx, clientCtx, txf, c.signer, mode, msgs...)
+	if isSequenceMismatch(res, err) {
+		c.syncNonce()
+		txf = txf.WithSequence(c.accSeq)
+		txf = txf.WithAccountNumber(c.accNum)
+		log.Debugln("retrying broadcastTx with nonce", c.accSeq)
+		res, err = c.broadcastTx(ctx, clientCtx, txf, c.signer, mode, msgs...)
+	}
+	if err != nil {
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		return nil, err
+	}
 
-	return res, nil
+	if isSequenceConsumed(res.TxResponse) {
+		c.accSeq++
+	}
+
+	return res, ParseTxError(res.TxResponse)
 }
 
+// broadcastTx signs and broadcasts the Tx. BROADCAST_MODE_BLOCK is emulated by broadcasting
+// in SYNC mode and polling for the Tx inclusion, until ctx is done or the broadcast timeout passes.
 func (c *chainClient) broadcastTx(
+	ctx context.Context,
 	clientCtx client.Context,
 	txf tx.Factory,
-	await bool,
+	signer Signer,
+	mode txtypes.BroadcastMode,
 	msgs ...sdk.Msg,
 ) (*txtypes.BroadcastTxResponse, error) {
+	_, res, err := c.signAndBroadcastTx(ctx, clientCtx, txf, signer, mode, msgs...)
+	return res, err
+}
 
-	txf, err := c.prepareFactory(clientCtx, txf)
+// signAndBroadcastTx is broadcastTx that also returns the signed Tx bytes, so the Tx
+// can be broadcast again.
+func (c *chainClient) signAndBroadcastTx(
+	ctx context.Context,
+	clientCtx client.Context,
+	txf tx.Factory,
+	signer Signer,
+	mode txtypes.BroadcastMode,
+	msgs ...sdk.Msg,
+) ([]byte, *txtypes.BroadcastTxResponse, error) {
+
+	txf, err := c.prepareFactory(ctx, clientCtx, txf)
 	if err != nil {
 		err = errors.Wrap(err, "failed to prepareFactory")
-		return nil, err
+		return nil, nil, err
 	}
-	ctx := context.Background()
+	txf = c.withTimeoutHeight(ctx, txf)
+
 	if clientCtx.Simulate {
-		simTxBytes, err := tx.BuildSimTx(txf, msgs...)
+		adjustedGas, err := c.simulateGas(ctx, txf, signer.PubKey(), msgs)
 		if err != nil {
-			err = errors.Wrap(err, "failed to build sim tx bytes")
-			return nil, err
-		}
-		ctx := c.getCookie(ctx)
-		var header metadata.MD
-		simRes, err := c.txClient.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: simTxBytes}, grpc.Header(&header))
-		if err != nil {
-			err = errors.Wrap(err, "failed to CalculateGas")
-			return nil, err
+			return nil, nil, err
 		}
-
-		adjustedGas := uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed))
 		txf = txf.WithGas(adjustedGas)
 
-		c.gasWanted = adjustedGas
+		atomic.StoreUint64(&c.gasWanted, adjustedGas)
+	}
+
+	if txf, err = c.resolveFees(ctx, txf); err != nil {
+		return nil, nil, err
 	}
 
 	txn, err := tx.BuildUnsignedTx(txf, msgs...)
 
 	if err != nil {
 		err = errors.Wrap(err, "failed to BuildUnsignedTx")
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
 	}
 
 	txn.SetFeeGranter(clientCtx.GetFeeGranterAddress())
-	err = tx.Sign(txf, clientCtx.GetFromName(), txn, true)
-	if err != nil {
+	signerData := authsigning.SignerData{
+		ChainID:       txf.ChainID(),
+		AccountNumber: txf.AccountNumber(),
+		Sequence:      txf.Sequence(),
+	}
+	if err := signTx(ctx, c.opts.ChainConfig, clientCtx.TxConfig, txn, signerData, signer); err != nil {
 		err = errors.Wrap(err, "failed to Sign Tx")
-		return nil, err
+		return nil, nil, err
 	}
 
 	txBytes, err := clientCtx.TxConfig.TxEncoder()(txn.GetTx())
 	if err != nil {
 		err = errors.Wrap(err, "failed TxEncoder to encode Tx")
-		return nil, err
+		return nil, nil, err
+	}
+
+	res, err := c.broadcastTxBytes(ctx, clientCtx, txBytes, mode)
+	if err != nil {
+		return txBytes, res, err
+	}
+
+	var feeErr *ErrInsufficientFee
+	if errors.As(ParseTxError(res.TxResponse), &feeErr) {
+		c.fees.observeInsufficientFee(feeErr, txf.Gas())
+	} else if isSequenceConsumed(res.TxResponse) {
+		// the fee is charged once the Tx passed CheckTx
+		c.feeMux.Lock()
+		c.lastTxFee = txn.GetTx().GetFee()
+		c.feeMux.Unlock()
+	}
+
+	return txBytes, res, nil
+}
+
+// broadcastTxBytes broadcasts an encoded signed Tx. BLOCK mode is emulated by a SYNC
+// broadcast followed by awaiting the Tx inclusion until BroadcastTimeout.
+func (c *chainClient) broadcastTxBytes(
+	ctx context.Context,
+	clientCtx client.Context,
+	txBytes []byte,
+	mode txtypes.BroadcastMode,
+) (*txtypes.BroadcastTxResponse, error) {
+	await := mode == txtypes.BroadcastMode_BROADCAST_MODE_BLOCK
+	if await {
+		mode = txtypes.BroadcastMode_BROADCAST_MODE_SYNC
+	}
+
+	awaitCtx, cancelFn := context.WithTimeout(ctx, c.opts.BroadcastTimeout)
+	defer cancelFn()
+
+	// subscribe before broadcasting, so the inclusion event can't be missed
+	txHash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	var resultC <-chan *ctypes.ResultTx
+	if await {
+		var unsubscribe func()
+		resultC, unsubscribe = c.subscribeTx(awaitCtx, txHash)
+		defer unsubscribe()
 	}
 
 	req := txtypes.BroadcastTxRequest{
-		txBytes,
-		txtypes.BroadcastMode_BROADCAST_MODE_SYNC,
+		TxBytes: txBytes,
+		Mode:    mode,
 	}
 	// use our own client to broadcast tx
-	var header metadata.MD
-	ctx = c.getCookie(ctx)
-	res, err := c.txClient.BroadcastTx(ctx, &req, grpc.Header(&header))
-	if !await || err != nil {
-		return res, err
+	res, err := c.txClient.BroadcastTx(ctx, &req)
+	if err != nil {
+		err = ParseGRPCError(err)
+		if res = c.resolveDuplicateTx(ctx, txBytes, err); res == nil {
+			err = errors.Wrap(err, "failed to BroadcastTx")
+			return nil, err
+		}
+	} else if dupRes := c.resolveDuplicateTx(ctx, txBytes, ParseTxError(res.TxResponse)); dupRes != nil {
+		res = dupRes
 	}
 
-	awaitCtx, cancelFn := context.WithTimeout(context.Background(), defaultBroadcastTimeout)
-	defer cancelFn()
+	// the Tx was included by an earlier broadcast of it
+	if !await || res.TxResponse.Height > 0 {
+		return res, nil
+	}
+
+	// tx was rejected in CheckTx, it will never be included
+	if res.TxResponse.Code != 0 {
+		return res, nil
+	}
+
+	txRes, err := c.awaitTx(awaitCtx, txHash, resultC)
+	if err != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrapf(err, "%s", txHash)
+		}
+		err := errors.Wrapf(ErrTimedOut, "%s", txHash)
+		return nil, err
+	}
+
+	return &txtypes.BroadcastTxResponse{TxResponse: txRes}, nil
+}
 
-	txHash, _ := hex.DecodeString(res.TxResponse.TxHash)
-	t := time.NewTimer(defaultBroadcastStatusPoll)
+// subscribeTx subscribes to the inclusion event of the Tx. A nil channel is returned when
+// the subscription fails, the Tx inclusion is then only polled for.
+func (c *chainClient) subscribeTx(ctx context.Context, txHash string) (<-chan *ctypes.ResultTx, func()) {
+	resultC, unsubscribe, err := c.txSubs.subscribe(ctx, txHash)
+	if err != nil {
+		c.logger.WithError(err).Debugln("falling back to polling for tx inclusion")
+		return nil, func() {}
+	}
+
+	return resultC, unsubscribe
+}
+
+// awaitTx waits until the Tx is included in block, by its event when subscribed to it and by
+// polling for it via the endpoint pool, so it's found even when events are missed or the
+// websocket drops. Subscribed Txs are polled for at a slower pace. Returns once ctx is done.
+func (c *chainClient) awaitTx(ctx context.Context, txHash string, resultC <-chan *ctypes.ResultTx) (*sdk.TxResponse, error) {
+	pollInterval := c.opts.BroadcastStatusPoll
+	if resultC != nil {
+		pollInterval *= txEventsPollFactor
+	}
+
+	t := time.NewTicker(pollInterval)
+	defer t.Stop()
 
 	for {
 		select {
-		case <-awaitCtx.Done():
-			err := errors.Wrapf(ErrTimedOut, "%s", res.TxResponse.TxHash)
-			t.Stop()
-			return nil, err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case resultTx := <-resultC:
+			return sdk.NewResponseResultTx(resultTx, nil, ""), nil
 		case <-t.C:
-			resultTx, err := clientCtx.Client.Tx(awaitCtx, txHash, false)
+			getRes, err := c.txClient.GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
 			if err != nil {
-				if errRes := client.CheckTendermintError(err, txBytes); errRes != nil {
-					return &txtypes.BroadcastTxResponse{TxResponse: errRes}, err
+				if status.Code(err) != codes.NotFound {
+					c.logger.WithError(err).Debugln("failed to get tx:", txHash)
 				}
+				continue
+			} else if getRes.GetTxResponse() != nil && getRes.TxResponse.Height > 0 {
+				return getRes.TxResponse, nil
+			}
+		}
+	}
+}
 
-				// log.WithError(err).Warningln("Tx Error for Hash:", res.TxHash)
+// resolveDuplicateTx looks up the Tx by hash when it was rejected as a duplicate or with a stale
+// sequence, which happens when an earlier broadcast of the same Tx reached the node, e.g. before
+// a retry or failover. Returns the response of the included Tx, a response with only the hash
+// when it's pending in the mempool, or nil when the rejection wasn't caused by the Tx itself.
+func (c *chainClient) resolveDuplicateTx(ctx context.Context, txBytes []byte, broadcastErr error) *txtypes.BroadcastTxResponse {
+	var inMempoolErr *ErrTxInMempool
+	var seqErr *ErrSequenceMismatch
+	inMempool := errors.As(broadcastErr, &inMempoolErr)
+	if !inMempool && !errors.As(broadcastErr, &seqErr) {
+		return nil
+	}
 
-				t.Reset(defaultBroadcastStatusPoll)
-				continue
+	txHash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	getRes, err := c.txClient.GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
+	if err == nil && getRes.GetTxResponse() != nil {
+		c.logger.Debugln("tx has already been included:", txHash)
+		return &txtypes.BroadcastTxResponse{TxResponse: getRes.TxResponse}
+	}
 
-			} else if resultTx.Height > 0 {
-				resResultTx := sdk.NewResponseResultTx(resultTx, res.TxResponse.Tx, res.TxResponse.Timestamp)
-				res = &txtypes.BroadcastTxResponse{TxResponse: resResultTx}
-				t.Stop()
-				return res, err
-			}
+	if inMempool {
+		c.logger.Debugln("tx is already in mempool:", txHash)
+		return &txtypes.BroadcastTxResponse{TxResponse: &sdk.TxResponse{TxHash: txHash}}
+	}
 
-			t.Reset(defaultBroadcastStatusPoll)
-		}
+	return nil
+}
+
+// resolveFees estimates the Tx fees from the gas limit, unless they have been overridden for this Tx.
+func (c *chainClient) resolveFees(ctx context.Context, txf tx.Factory) (tx.Factory, error) {
+	if !txf.Fees().IsZero() || !txf.GasPrices().IsZero() {
+		return txf, nil
+	}
+
+	fees, err := c.fees.EstimateFee(ctx, txf.Gas())
+	if err != nil {
+		err = errors.Wrap(err, "failed to EstimateFee")
+		return txf, err
+	} else if !fees.IsZero() {
+		txf = txf.WithFees(fees.String())
 	}
+
+	return txf, nil
 }
 
 // QueueBroadcastMsg enqueues a list of messages. Messages will added to the queue
@@ -590,7 +852,7 @@ func (c *chainClient) QueueBroadcastMsg(msgs ...sdk.Msg) error {
 		select {
 		case <-t.C:
 			return ErrEnqueueTimeout
-		case c.msgC <- msg:
+		case c.msgC <- queuedMsg{msg: msg}:
 		}
 	}
 	t.Stop()
@@ -598,47 +860,88 @@ func (c *chainClient) QueueBroadcastMsg(msgs ...sdk.Msg) error {
 	return nil
 }
 
+// QueueBroadcastMsgWithResult enqueues a list of messages the same way QueueBroadcastMsg does,
+// but returns a future for every message that resolves with the tx hash, height and the
+// decoded message response, or with the error that caused the batch to fail.
+// If enqueueing times out, futures of messages that were not enqueued resolve with ErrEnqueueTimeout.
+func (c *chainClient) QueueBroadcastMsgWithResult(msgs ...sdk.Msg) ([]*MsgFuture, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
+	} else if atomic.LoadInt64(&c.closed) == 1 {
+		return nil, ErrQueueClosed
+	}
+
+	futures := make([]*MsgFuture, 0, len(msgs))
+	for range msgs {
+		futures = append(futures, newMsgFuture())
+	}
+
+	t := time.NewTimer(10 * time.Second)
+	defer t.Stop()
+
+	for idx, msg := range msgs {
+		select {
+		case <-t.C:
+			for _, future := range futures[idx:] {
+				future.resolve(MsgResult{Err: ErrEnqueueTimeout})
+			}
+			return futures, ErrEnqueueTimeout
+		case c.msgC <- queuedMsg{msg: msg, future: futures[idx]}:
+		}
+	}
+
+	return futures, nil
+}
+
+// PipelineBroadcastMsg signs and broadcasts a Tx with the next local sequence, without waiting
+// for the previous Txs to be included in block. The returned futures resolve once the Tx is included,
+// or with an error if the Tx has been rejected or dropped.
+func (c *chainClient) PipelineBroadcastMsg(msgs ...sdk.Msg) ([]*MsgFuture, error) {
+	if !c.canSign {
+		return nil, ErrReadOnly
+	} else if atomic.LoadInt64(&c.closed) == 1 {
+		return nil, ErrQueueClosed
+	}
+
+	futures := make([]*MsgFuture, 0, len(msgs))
+	batch := make([]queuedMsg, 0, len(msgs))
+	for _, msg := range msgs {
+		future := newMsgFuture()
+		futures = append(futures, future)
+		batch = append(batch, queuedMsg{msg: msg, future: future})
+	}
+
+	c.syncMux.Lock()
+	defer c.syncMux.Unlock()
+
+	if err := c.pipeline.submit(batch); err != nil {
+		return futures, err
+	}
+
+	return futures, nil
+}
+
 func (c *chainClient) runBatchBroadcast() {
-	expirationTimer := time.NewTimer(msgCommitBatchTimeLimit)
-	msgBatch := make([]sdk.Msg, 0, msgCommitBatchSizeLimit)
+	expirationTimer := time.NewTimer(c.opts.BatchTimeLimit)
+	msgBatch := make([]queuedMsg, 0, c.opts.BatchSizeLimit)
 
-	submitBatch := func(toSubmit []sdk.Msg) {
+	submitBatch := func(batch []queuedMsg) {
 		c.syncMux.Lock()
 		defer c.syncMux.Unlock()
-		c.txFactory = c.txFactory.WithSequence(c.accSeq)
-		c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-		log.Debugln("broadcastTx with nonce", c.accSeq)
-		res, err := c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
-		if err != nil {
-			if strings.Contains(err.Error(), "account sequence mismatch") {
-				c.syncNonce()
-				c.txFactory = c.txFactory.WithSequence(c.accSeq)
-				c.txFactory = c.txFactory.WithAccountNumber(c.accNum)
-				log.Debugln("retrying broadcastTx with nonce", c.accSeq)
-				res, err = c.broadcastTx(c.ctx, c.txFactory, true, toSubmit...)
-			}
-			if err != nil {
-				resJSON, _ := json.MarshalIndent(res, "", "\t")
-				c.logger.WithField("size", len(toSubmit)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
-				return
+
+		for _, chunk := range c.splitBatchByGas(batch) {
+			if c.opts.PipelinedBroadcast {
+				_ = c.pipeline.submit(chunk)
+				continue
 			}
-		}
 
-		if res.TxResponse.Code != 0 {
-			err = errors.Errorf("error %d (%s): %s", res.TxResponse.Code, res.TxResponse.Codespace, res.TxResponse.RawLog)
-			log.WithField("txHash", res.TxResponse.TxHash).WithError(err).Errorln("failed to commit msg batch")
-		} else {
-			log.WithField("txHash", res.TxResponse.TxHash).Debugln("msg batch committed successfully at height", res.TxResponse.Height)
+			c.commitBatch(chunk)
 		}
-
-		c.accSeq++
-		log.Debugln("nonce incremented to", c.accSeq)
-		log.Debugln("gas wanted: ", c.gasWanted)
 	}
 
 	for {
 		select {
-		case msg, ok := <-c.msgC:
+		case queued, ok := <-c.msgC:
 			if !ok {
 				// exit required
 				if len(msgBatch) > 0 {
@@ -649,42 +952,91 @@ func (c *chainClient) runBatchBroadcast() {
 				return
 			}
 
-			msgBatch = append(msgBatch, msg)
+			msgBatch = append(msgBatch, queued)
 
-			if len(msgBatch) >= msgCommitBatchSizeLimit {
+			if len(msgBatch) >= c.opts.BatchSizeLimit {
 				toSubmit := msgBatch
-				msgBatch = msgBatch[:0]
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
+				msgBatch = make([]queuedMsg, 0, c.opts.BatchSizeLimit)
+				expirationTimer.Reset(c.opts.BatchTimeLimit)
 
 				submitBatch(toSubmit)
 			}
 		case <-expirationTimer.C:
 			if len(msgBatch) > 0 {
 				toSubmit := msgBatch
-				msgBatch = msgBatch[:0]
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
+				msgBatch = make([]queuedMsg, 0, c.opts.BatchSizeLimit)
+				expirationTimer.Reset(c.opts.BatchTimeLimit)
 				submitBatch(toSubmit)
 			} else {
-				expirationTimer.Reset(msgCommitBatchTimeLimit)
+				expirationTimer.Reset(c.opts.BatchTimeLimit)
 			}
 		}
 	}
 }
 
-func (c *chainClient) GetGasFee() (string, error) {
-	gasPrices := strings.Trim(c.opts.GasPrices, "inj")
-
-	gas, err := strconv.ParseFloat(gasPrices, 64)
+// commitBatch broadcasts the batch as a single Tx and waits until it is included in block.
+// Batches failing with out of gas are bisected and the halves are committed separately.
+// Must be called with c.syncMux held.
+func (c *chainClient) commitBatch(batch []queuedMsg) {
+	toSubmit := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		toSubmit = append(toSubmit, queued.msg)
+	}
 
+	txf := c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+	log.Debugln("broadcastTx with nonce", c.accSeq)
+	res, err := c.broadcastTx(context.Background(), c.ctx, txf, c.signer, txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, toSubmit...)
+	if isSequenceMismatch(res, err) {
+		c.syncNonce()
+		txf = c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+		log.Debugln("retrying broadcastTx with nonce", c.accSeq)
+		res, err = c.broadcastTx(context.Background(), c.ctx, txf, c.signer, txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, toSubmit...)
+	}
 	if err != nil {
-		return "", err
+		resJSON, _ := json.MarshalIndent(res, "", "\t")
+		c.logger.WithField("size", len(toSubmit)).WithError(err).Errorln("failed to commit msg batch:", string(resJSON))
+		resolveBatch(batch, res.GetTxResponse(), err)
+		return
+	}
+
+	if isSequenceConsumed(res.TxResponse) {
+		c.accSeq++
+		log.Debugln("nonce incremented to", c.accSeq)
+		log.Debugln("gas wanted: ", atomic.LoadUint64(&c.gasWanted))
+	}
+
+	if isOutOfGas(res.TxResponse) && len(batch) > 1 {
+		log.WithField("txHash", res.TxResponse.TxHash).Warningln("msg batch ran out of gas, retrying in halves of", len(batch)/2)
+		c.commitBatch(batch[:len(batch)/2])
+		c.commitBatch(batch[len(batch)/2:])
+		return
 	}
 
-	gasFeeAdjusted := gas * float64(c.gasWanted) / math.Pow(10, 18)
-	gasFeeFormatted := strconv.FormatFloat(gasFeeAdjusted, 'f', -1, 64)
-	c.gasFee = gasFeeFormatted
+	if err = ParseTxError(res.TxResponse); err != nil {
+		log.WithField("txHash", res.TxResponse.TxHash).WithError(err).Errorln("failed to commit msg batch")
+	} else {
+		log.WithField("txHash", res.TxResponse.TxHash).Debugln("msg batch committed successfully at height", res.TxResponse.Height)
+	}
+
+	resolveBatch(batch, res.TxResponse, err)
+}
+
+// EstimateFee returns the fee a Tx with the gas limit would pay, based on the configured
+// and the node minimum gas prices.
+func (c *chainClient) EstimateFee(ctx context.Context, gas uint64) (sdk.Coins, error) {
+	return c.fees.EstimateFee(ctx, gas)
+}
+
+// GetGasFee returns the fee paid by the last Tx the client has broadcast successfully.
+func (c *chainClient) GetGasFee() (sdk.Coins, error) {
+	c.feeMux.RLock()
+	defer c.feeMux.RUnlock()
+
+	if c.lastTxFee == nil {
+		return nil, errors.New("no tx has been broadcast yet")
+	}
 
-	return c.gasFee, err
+	return c.lastTxFee, nil
 }
 
 func (c *chainClient) DefaultSubaccount(acc cosmtypes.AccAddress) eth.Hash {
@@ -785,3 +1137,29 @@ func (c *chainClient) RawContractState(
 		},
 	)
 }
+
+// syncLock is a mutex that can also be acquired with a ctx, so callers waiting for
+// the account sequence give up once their ctx is done.
+type syncLock chan struct{}
+
+func newSyncLock() syncLock {
+	return make(syncLock, 1)
+}
+
+func (l syncLock) Lock() {
+	l <- struct{}{}
+}
+
+// LockContext acquires the lock, unless ctx is done first.
+func (l syncLock) LockContext(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l syncLock) Unlock() {
+	<-l
+}
@@ -0,0 +1,182 @@
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func newTestMsgSend(signer Signer) sdk.Msg {
+	return &banktypes.MsgSend{
+		FromAddress: signer.Address().String(),
+		ToAddress:   signer.Address().String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("aaa", 1)),
+	}
+}
+
+func TestChainClientFailsOverAccountQueriesAndInclusionPolling(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+	b := startFakeNode(t, "bbb", chain)
+
+	// the node that would serve the initial account query is down
+	a.server.Stop()
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a, b})
+	if num := chain.account(signer.Address()).num; c.accNum != num {
+		t.Fatalf("expected account number %d, got %d", num, c.accNum)
+	}
+
+	res, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if res.TxResponse.Height == 0 {
+		t.Fatalf("expected the tx to be included, got %+v", res.TxResponse)
+	}
+}
+
+func TestReadOnlyChainClientClose(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+
+	c, err := NewChainClient(newTestClientContext(t), a.addr)
+	if err != nil {
+		t.Fatal(err)
+	} else if c.CanSignTransactions() {
+		t.Fatal("expected a client without keyring to be read-only")
+	}
+
+	if denom := servedBy(t, c.QueryClient()); denom != "aaa" {
+		t.Fatalf("unexpected denom %s", denom)
+	}
+
+	c.Close()
+	c.Close()
+
+	if _, err := c.GetBankBalance(context.Background(), "", "aaa"); err == nil {
+		t.Fatal("expected the connections of the closed client to be closed")
+	}
+}
+
+func TestChainClientCloseIsIdempotent(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+
+	c := newTestChainClient(t, newTestSigner(), []*fakeNode{a})
+	c.Close()
+	c.Close()
+
+	if err := c.QueueBroadcastMsg(newTestMsgSend(c.signer)); err != ErrQueueClosed {
+		t.Fatalf("expected ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestChainClientTimeoutHeightFollowsLatestBlock(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionTimeoutHeight(20))
+
+	for i := 0; i < 3; i++ {
+		height := chain.latestHeight()
+		awaitCachedHeight(t, c, height)
+		if _, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, newTestMsgSend(signer)); err != nil {
+			t.Fatal(err)
+		}
+
+		broadcasts := chain.sentBroadcasts()
+		if got := broadcasts[len(broadcasts)-1].timeoutHeight; got != uint64(height)+20 {
+			t.Fatalf("expected timeout height %d, got %d", height+20, got)
+		}
+
+		// the next Tx is sent long after the timeout height of this one
+		for j := 0; j < 50; j++ {
+			chain.commit()
+		}
+	}
+}
+
+func TestChainClientTimeoutHeightUsesCachedHeight(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionTimeoutHeightSyncInterval(time.Hour))
+	awaitCachedHeight(t, c, chain.latestHeight())
+
+	for j := 0; j < 50; j++ {
+		chain.commit()
+	}
+
+	// the latest block is not queried per Tx, the cached height is used until the next sync
+	txf := c.withTimeoutHeight(context.Background(), c.txFactory)
+	if got := txf.TimeoutHeight(); got != uint64(chain.latestHeight())-50+20 {
+		t.Fatalf("expected timeout height %d, got %d", chain.latestHeight()-50+20, got)
+	}
+}
+
+func TestChainClientTimeoutHeightOverrides(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	overrides := TxOverrides{TimeoutHeight: 500}
+	if _, err := c.BroadcastMsgsWithOverrides(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, overrides, newTestMsgSend(signer)); err != nil {
+		t.Fatal(err)
+	}
+
+	disabled := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionTimeoutHeight(0))
+	if _, err := disabled.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, newTestMsgSend(signer)); err != nil {
+		t.Fatal(err)
+	}
+
+	broadcasts := chain.sentBroadcasts()
+	if got := broadcasts[0].timeoutHeight; got != 500 {
+		t.Errorf("expected the overridden timeout height 500, got %d", got)
+	} else if got := broadcasts[1].timeoutHeight; got != 0 {
+		t.Errorf("expected no timeout height when disabled, got %d", got)
+	}
+}
+
+func TestChainClientBroadcastHonorsContextWhileSequenceIsLocked(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	// another broadcast of the account is stuck
+	c.syncMux.Lock()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelFn()
+
+	start := time.Now()
+	if _, err := c.BroadcastMsgs(ctx, txtypes.BroadcastMode_BROADCAST_MODE_SYNC, newTestMsgSend(signer)); err != context.DeadlineExceeded {
+		t.Fatalf("expected the broadcast to give up waiting, got %v", err)
+	} else if _, err := c.SimulateMsgs(ctx, newTestMsgSend(signer)); err != context.DeadlineExceeded {
+		t.Fatalf("expected the simulation to give up waiting, got %v", err)
+	} else if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected to return at the deadline, took %s", elapsed)
+	}
+
+	c.syncMux.Unlock()
+
+	if _, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, newTestMsgSend(signer)); err != nil {
+		t.Fatal(err)
+	} else if len(chain.sentBroadcasts()) != 1 {
+		t.Fatalf("expected only the broadcast that got the lock to be sent, got %d", len(chain.sentBroadcasts()))
+	}
+}
@@ -17,8 +17,8 @@ import (
 
 	chaintypes "github.com/gotabit/sdk-go/chain/types"
 	wasmx "github.com/gotabit/sdk-go/chain/wasmx/types"
+	"github.com/gotabit/sdk-go/client/common"
 
-	cosmostypes "github.com/cosmos/cosmos-sdk/types"
 	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	vestingtypes "github.com/cosmos/cosmos-sdk/x/auth/vesting/types"
@@ -74,6 +74,14 @@ func NewTxConfig(signModes []signingtypes.SignMode) client.TxConfig {
 // of the Cosmos account. Keyring is required to contain the specified key.
 func NewClientContext(
 	chainId, fromSpec string, kb keyring.Keyring,
+) (client.Context, error) {
+	return NewClientContextWithConfig(common.DefaultChainConfig(), chainId, fromSpec, kb)
+}
+
+// NewClientContextWithConfig creates a new Cosmos Client context like NewClientContext,
+// for a chain with the bech32 prefixes of chainConfig.
+func NewClientContextWithConfig(
+	chainConfig common.ChainConfig, chainId, fromSpec string, kb keyring.Keyring,
 ) (client.Context, error) {
 	clientCtx := client.Context{}
 
@@ -114,11 +122,11 @@ func NewClientContext(
 	var keyInfo keyring.Info
 
 	if kb != nil {
-		addr, err := cosmostypes.AccAddressFromBech32(fromSpec)
+		addr, err := chainConfig.ParseAccAddress(fromSpec)
 		if err == nil {
 			keyInfo, err = kb.KeyByAddress(addr)
 			if err != nil {
-				err = errors.Wrapf(err, "failed to load key info by address %s", addr.String())
+				err = errors.Wrapf(err, "failed to load key info by address %s", fromSpec)
 				return clientCtx, err
 			}
 		} else {
@@ -133,6 +141,7 @@ func NewClientContext(
 
 	clientCtx = newContext(
 		chainId,
+		chainConfig,
 		encodingConfig,
 		kb,
 		keyInfo,
@@ -149,6 +158,7 @@ type EncodingConfig struct {
 
 func newContext(
 	chainId string,
+	chainConfig common.ChainConfig,
 	encodingConfig EncodingConfig,
 	kb keyring.Keyring,
 	keyInfo keyring.Info,
@@ -164,11 +174,9 @@ func newContext(
 		BroadcastMode:     "block",
 		UseLedger:         false,
 		Simulate:          true,
-		GenerateOnly:      false,
-		Offline:           false,
 		SkipConfirm:       true,
 		TxConfig:          encodingConfig.TxConfig,
-		AccountRetriever:  authtypes.AccountRetriever{},
+		AccountRetriever:  newAccountRetriever(nil, chainConfig),
 	}
 
 	if keyInfo != nil {
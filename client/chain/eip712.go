@@ -0,0 +1,771 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/auth/legacy/legacytx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtx "github.com/cosmos/cosmos-sdk/x/auth/tx"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+	chaintypes "github.com/gotabit/sdk-go/chain/types"
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// EIP-712 domain of Cosmos Txs signed by Web3 wallets
+const (
+	eip712DomainName        = "Cosmos Web3"
+	eip712DomainVersion     = "1.0.0"
+	eip712VerifyingContract = "cosmos"
+	eip712Salt              = "0"
+
+	eip712MsgValueType = "MsgValue"
+	eip712TypeDefRoot  = "_"
+)
+
+var (
+	eip712StringTypes = []reflect.Type{
+		reflect.TypeOf(sdk.Int{}),
+		reflect.TypeOf(sdk.Dec{}),
+		reflect.TypeOf(sdk.Uint{}),
+		reflect.TypeOf(big.Int{}),
+		reflect.TypeOf(time.Time{}),
+	}
+	anyType = reflect.TypeOf(codectypes.Any{})
+)
+
+// EIP712Type is a member of an EIP-712 struct type.
+type EIP712Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EIP712Types are the EIP-712 struct types by name.
+type EIP712Types map[string][]EIP712Type
+
+// EIP712Domain is the domain of EIP-712 typed data, empty members are left out of it.
+type EIP712Domain struct {
+	Name              string `json:"name,omitempty"`
+	Version           string `json:"version,omitempty"`
+	ChainID           uint64 `json:"chainId,omitempty"`
+	VerifyingContract string `json:"verifyingContract,omitempty"`
+	Salt              string `json:"salt,omitempty"`
+}
+
+// EIP712TypedData is the typed data a Web3 wallet signs with eth_signTypedData_v4.
+type EIP712TypedData struct {
+	Types       EIP712Types            `json:"types"`
+	PrimaryType string                 `json:"primaryType"`
+	Domain      EIP712Domain           `json:"domain"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// EIP712TypedData converts the Tx into the EIP-712 typed data a Web3 wallet like MetaMask signs for it.
+// The message is the amino JSON sign doc of the Tx extended with the fee payer, which is the signer
+// unless set explicitly, and the domain carries the Ethereum chain ID. All the msgs of the Tx must
+// be of the same type.
+func (o *OfflineTx) EIP712TypedData(
+	txConfig client.TxConfig,
+	chainConfig common.ChainConfig,
+	ethChainID uint64,
+	signer sdk.AccAddress,
+) (EIP712TypedData, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return EIP712TypedData{}, err
+	}
+
+	signerData := authsigning.SignerData{
+		ChainID:       o.ChainID,
+		AccountNumber: o.AccountNumber,
+		Sequence:      o.Sequence,
+	}
+
+	return eip712TypedData(signerData, ethChainID, eip712FeePayer(chainConfig, txn.GetTx(), signer), txn.GetTx())
+}
+
+// SignEIP712 signs the EIP-712 typed data of the Tx with privKey and returns the encoded signed Tx.
+// The signature is attached with the ExtensionOptionsWeb3Tx extension, along with the typed data
+// chain ID and the fee payer, the way Txs signed by Web3 wallets are broadcast.
+func (o *OfflineTx) SignEIP712(
+	txConfig client.TxConfig,
+	chainConfig common.ChainConfig,
+	ethChainID uint64,
+	privKey *ethsecp256k1.PrivKey,
+) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	extTxn, ok := txn.(authtx.ExtensionOptionsTxBuilder)
+	if !ok {
+		return nil, errors.New("tx builder doesn't support extension options")
+	}
+
+	signerData := authsigning.SignerData{
+		ChainID:       o.ChainID,
+		AccountNumber: o.AccountNumber,
+		Sequence:      o.Sequence,
+	}
+
+	feePayer := eip712FeePayer(chainConfig, txn.GetTx(), sdk.AccAddress(privKey.PubKey().Address()))
+	typedData, err := eip712TypedData(signerData, ethChainID, feePayer, txn.GetTx())
+	if err != nil {
+		return nil, err
+	}
+
+	sigHashPreimage, err := eip712SigHashPreimage(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	// ethsecp256k1 keys sign the Keccak256 hash of the payload
+	sigBytes, err := privKey.Sign(sigHashPreimage)
+	if err != nil {
+		err = errors.Wrap(err, "failed to sign EIP-712 typed data")
+		return nil, err
+	}
+
+	extOpt, err := codectypes.NewAnyWithValue(&chaintypes.ExtensionOptionsWeb3Tx{
+		TypedDataChainID: ethChainID,
+		FeePayer:         feePayer,
+		FeePayerSig:      sigBytes,
+	})
+	if err != nil {
+		err = errors.Wrap(err, "failed to pack web3 tx extension")
+		return nil, err
+	}
+	extTxn.SetExtensionOptions(extOpt)
+
+	// the signature is carried by the extension, the signer info only declares the sign mode
+	sig := signingtypes.SignatureV2{
+		PubKey: privKey.PubKey(),
+		Data: &signingtypes.SingleSignatureData{
+			SignMode: signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		},
+		Sequence: o.Sequence,
+	}
+	if err := txn.SetSignatures(sig); err != nil {
+		err = errors.Wrap(err, "failed to set signer info")
+		return nil, err
+	}
+
+	return encodeTx(txConfig, txn)
+}
+
+// EIP712SigHash returns the hash that is signed for the typed data:
+// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message)).
+func EIP712SigHash(typedData EIP712TypedData) ([]byte, error) {
+	sigHashPreimage, err := eip712SigHashPreimage(typedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.Keccak256(sigHashPreimage), nil
+}
+
+func eip712SigHashPreimage(typedData EIP712TypedData) ([]byte, error) {
+	domainSeparator, err := typedData.hashStruct("EIP712Domain", typedData.Domain.message())
+	if err != nil {
+		err = errors.Wrap(err, "failed to hash EIP-712 domain")
+		return nil, err
+	}
+
+	typedDataHash, err := typedData.hashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		err = errors.Wrap(err, "failed to hash EIP-712 message")
+		return nil, err
+	}
+
+	preimage := make([]byte, 0, 2+len(domainSeparator)+len(typedDataHash))
+	preimage = append(preimage, 0x19, 0x01)
+	preimage = append(preimage, domainSeparator...)
+	preimage = append(preimage, typedDataHash...)
+
+	return preimage, nil
+}
+
+// message returns the domain as the message of the EIP712Domain type.
+func (d EIP712Domain) message() map[string]interface{} {
+	message := map[string]interface{}{}
+	if len(d.Name) > 0 {
+		message["name"] = d.Name
+	}
+	if len(d.Version) > 0 {
+		message["version"] = d.Version
+	}
+	if d.ChainID > 0 {
+		message["chainId"] = d.ChainID
+	}
+	if len(d.VerifyingContract) > 0 {
+		message["verifyingContract"] = d.VerifyingContract
+	}
+	if len(d.Salt) > 0 {
+		message["salt"] = d.Salt
+	}
+
+	return message
+}
+
+// hashStruct returns keccak256(typeHash ‖ encodeData(data)) of the struct type.
+func (typedData EIP712TypedData) hashStruct(typeName string, data map[string]interface{}) ([]byte, error) {
+	members, ok := typedData.Types[typeName]
+	if !ok {
+		return nil, errors.Errorf("unknown EIP-712 type %s", typeName)
+	} else if len(data) > len(members) {
+		return nil, errors.Errorf("%s has %d members, got %d values", typeName, len(members), len(data))
+	}
+
+	buf := new(bytes.Buffer)
+	buf.Write(crypto.Keccak256([]byte(typedData.encodeType(typeName))))
+	for _, member := range members {
+		encoded, err := typedData.encodeValue(member.Type, data[member.Name])
+		if err != nil {
+			err = errors.Wrapf(err, "failed to encode %s.%s", typeName, member.Name)
+			return nil, err
+		}
+		buf.Write(encoded)
+	}
+
+	return crypto.Keccak256(buf.Bytes()), nil
+}
+
+// encodeType encodes the struct type followed by the struct types it references, sorted by name,
+// e.g. Tx(Fee fee,...)Coin(string denom,string amount)Fee(Coin[] amount,...).
+func (typedData EIP712TypedData) encodeType(typeName string) string {
+	deps := map[string]bool{}
+	typedData.collectDependencies(typeName, deps)
+	delete(deps, typeName)
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var encoded strings.Builder
+	for _, name := range append([]string{typeName}, names...) {
+		members := make([]string, 0, len(typedData.Types[name]))
+		for _, member := range typedData.Types[name] {
+			members = append(members, member.Type+" "+member.Name)
+		}
+
+		encoded.WriteString(name + "(" + strings.Join(members, ",") + ")")
+	}
+
+	return encoded.String()
+}
+
+func (typedData EIP712TypedData) collectDependencies(typeName string, deps map[string]bool) {
+	typeName = strings.Split(typeName, "[")[0]
+	if _, ok := typedData.Types[typeName]; !ok || deps[typeName] {
+		return
+	}
+
+	deps[typeName] = true
+	for _, member := range typedData.Types[typeName] {
+		typedData.collectDependencies(member.Type, deps)
+	}
+}
+
+// encodeValue encodes the value as a 32 byte word. Structs and arrays are encoded as the hash of
+// their members, strings as their hash.
+func (typedData EIP712TypedData) encodeValue(typeName string, value interface{}) ([]byte, error) {
+	if idx := strings.LastIndex(typeName, "["); idx > 0 && strings.HasSuffix(typeName, "]") {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, errors.Errorf("%v is not a %s", value, typeName)
+		}
+
+		buf := new(bytes.Buffer)
+		for _, item := range items {
+			encoded, err := typedData.encodeValue(typeName[:idx], item)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(encoded)
+		}
+
+		return crypto.Keccak256(buf.Bytes()), nil
+	}
+
+	if _, ok := typedData.Types[typeName]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("%v is not a %s", value, typeName)
+		}
+
+		return typedData.hashStruct(typeName, data)
+	}
+
+	switch typeName {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, errors.Errorf("%v is not a string", value)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+	case "bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, errors.Errorf("%v is not a bool", value)
+		}
+		word := make([]byte, 32)
+		if b {
+			word[31] = 1
+		}
+		return word, nil
+	case "address":
+		str, ok := value.(string)
+		if !ok || !ethcommon.IsHexAddress(str) {
+			return nil, errors.Errorf("%v is not an address", value)
+		}
+		return ethcommon.LeftPadBytes(ethcommon.HexToAddress(str).Bytes(), 32), nil
+	}
+
+	if strings.HasPrefix(typeName, "int") || strings.HasPrefix(typeName, "uint") {
+		n, err := eip712Integer(typeName, value)
+		if err != nil {
+			return nil, err
+		}
+		return math.U256Bytes(n), nil
+	}
+
+	return nil, errors.Errorf("unsupported EIP-712 type %s", typeName)
+}
+
+// eip712Integer parses an integer value of the intN or uintN type, given as a JSON number,
+// a decimal or hex string, or a Go integer.
+func eip712Integer(typeName string, value interface{}) (*big.Int, error) {
+	signed := strings.HasPrefix(typeName, "int")
+	bits := 256
+	if size := strings.TrimPrefix(strings.TrimPrefix(typeName, "u"), "int"); len(size) > 0 {
+		var err error
+		if bits, err = strconv.Atoi(size); err != nil || bits <= 0 || bits > 256 || bits%8 != 0 {
+			return nil, errors.Errorf("invalid EIP-712 integer type %s", typeName)
+		}
+	}
+
+	var n *big.Int
+	switch v := value.(type) {
+	case string:
+		n, _ = math.ParseBig256(v)
+	case json.Number:
+		n, _ = math.ParseBig256(v.String())
+	case float64:
+		if float64(int64(v)) == v {
+			n = big.NewInt(int64(v))
+		}
+	case uint64:
+		n = new(big.Int).SetUint64(v)
+	case int64:
+		n = big.NewInt(v)
+	case int:
+		n = big.NewInt(int64(v))
+	case *big.Int:
+		n = new(big.Int).Set(v)
+	}
+
+	if n == nil {
+		return nil, errors.Errorf("%v is not a %s", value, typeName)
+	}
+
+	// values range in [min, max)
+	max := new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	min := new(big.Int)
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+	if n.Cmp(min) < 0 || n.Cmp(max) >= 0 {
+		return nil, errors.Errorf("%v is out of the %s range", value, typeName)
+	}
+
+	return n, nil
+}
+
+// eip712FeePayer returns the fee payer set in the Tx, or the signer formatted with the chain config.
+// Tx.FeePayer isn't used, it decodes the msg signers with the global bech32 config.
+func eip712FeePayer(chainConfig common.ChainConfig, tx authsigning.Tx, signer sdk.AccAddress) string {
+	if protoTx, ok := tx.(interface{ GetProtoTx() *txtypes.Tx }); ok {
+		if payer := protoTx.GetProtoTx().GetAuthInfo().GetFee().GetPayer(); len(payer) > 0 {
+			return payer
+		}
+	}
+
+	return chainConfig.FormatAccAddress(signer)
+}
+
+func eip712TypedData(signerData authsigning.SignerData, ethChainID uint64, feePayer string, tx authsigning.Tx) (EIP712TypedData, error) {
+	msgs := tx.GetMsgs()
+	if len(msgs) == 0 {
+		return EIP712TypedData{}, errors.New("tx has no msgs")
+	}
+
+	signDoc := legacytx.StdSignBytes(
+		signerData.ChainID,
+		signerData.AccountNumber,
+		signerData.Sequence,
+		tx.GetTimeoutHeight(),
+		legacytx.StdFee{Amount: tx.GetFee(), Gas: tx.GetGas()},
+		msgs,
+		tx.GetMemo(),
+	)
+
+	message := map[string]interface{}{}
+	if err := json.Unmarshal(signDoc, &message); err != nil {
+		err = errors.Wrap(err, "failed to unmarshal amino JSON sign doc")
+		return EIP712TypedData{}, err
+	}
+
+	fee, ok := message["fee"].(map[string]interface{})
+	if !ok {
+		return EIP712TypedData{}, errors.New("sign doc has no fee")
+	}
+	fee["feePayer"] = feePayer
+
+	types := EIP712Types{
+		"EIP712Domain": {
+			{Name: "name", Type: "string"},
+			{Name: "version", Type: "string"},
+			{Name: "chainId", Type: "uint256"},
+			{Name: "verifyingContract", Type: "string"},
+			{Name: "salt", Type: "string"},
+		},
+		"Tx": {
+			{Name: "account_number", Type: "string"},
+			{Name: "chain_id", Type: "string"},
+			{Name: "fee", Type: "Fee"},
+			{Name: "memo", Type: "string"},
+			{Name: "msgs", Type: "Msg[]"},
+			{Name: "sequence", Type: "string"},
+		},
+		"Fee": {
+			{Name: "amount", Type: "Coin[]"},
+			{Name: "gas", Type: "string"},
+			{Name: "feePayer", Type: "string"},
+		},
+		"Coin": {
+			{Name: "denom", Type: "string"},
+			{Name: "amount", Type: "string"},
+		},
+		"Msg": {
+			{Name: "type", Type: "string"},
+			{Name: "value", Type: eip712MsgValueType},
+		},
+	}
+	// omitted from the sign doc when not set
+	if _, ok := message["timeout_height"]; ok {
+		types["Tx"] = append(types["Tx"], EIP712Type{Name: "timeout_height", Type: "string"})
+	}
+
+	msgDocs, ok := message["msgs"].([]interface{})
+	if !ok || len(msgDocs) != len(msgs) {
+		return EIP712TypedData{}, errors.New("sign doc msgs don't match tx msgs")
+	}
+
+	var msgTypes EIP712Types
+	for idx, msg := range msgs {
+		msgDoc, ok := msgDocs[idx].(map[string]interface{})
+		if !ok {
+			return EIP712TypedData{}, errors.Errorf("sign doc msg %d is not an object", idx)
+		}
+		value, ok := msgDoc["value"].(map[string]interface{})
+		if !ok {
+			return EIP712TypedData{}, errors.Errorf("sign doc msg %d has no value", idx)
+		}
+
+		walker := &eip712TypeWalker{types: EIP712Types{}}
+		if err := walker.walkStruct(eip712MsgValueType, eip712TypeDefRoot, reflect.ValueOf(msg), value); err != nil {
+			err = errors.Wrapf(err, "failed to infer EIP-712 types of %s", sdk.MsgTypeURL(msg))
+			return EIP712TypedData{}, err
+		}
+
+		if msgTypes == nil {
+			msgTypes = walker.types
+		} else if !reflect.DeepEqual(msgTypes, walker.types) {
+			return EIP712TypedData{}, errors.New("all tx msgs must have the same EIP-712 types")
+		}
+	}
+
+	for name, fields := range msgTypes {
+		types[name] = fields
+	}
+
+	return EIP712TypedData{
+		Types:       types,
+		PrimaryType: "Tx",
+		Domain: EIP712Domain{
+			Name:              eip712DomainName,
+			Version:           eip712DomainVersion,
+			ChainID:           ethChainID,
+			VerifyingContract: eip712VerifyingContract,
+			Salt:              eip712Salt,
+		},
+		Message: message,
+	}, nil
+}
+
+// eip712TypeWalker infers the EIP-712 types of a msg from its Go struct, in field order,
+// keeping only the fields present in its amino JSON encoding. Nested types are named
+// after their path in the msg, e.g. the amount of MsgSend is TypeAmount.
+type eip712TypeWalker struct {
+	types EIP712Types
+}
+
+func (w *eip712TypeWalker) walkStruct(typeName, prefix string, v reflect.Value, doc map[string]interface{}) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return errors.Errorf("%s is nil", prefix)
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return errors.Errorf("%s is a %s, not a struct", prefix, v.Kind())
+	}
+
+	fields := make([]EIP712Type, 0, v.NumField())
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if structField.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		value, ok := doc[name]
+		if !ok {
+			// omitted from the amino JSON
+			continue
+		}
+
+		fieldType, err := w.fieldType(prefix+"."+name, v.Field(i), value)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, EIP712Type{Name: name, Type: fieldType})
+	}
+
+	w.types[typeName] = fields
+	return nil
+}
+
+func (w *eip712TypeWalker) fieldType(prefix string, v reflect.Value, value interface{}) (string, error) {
+	if value == nil {
+		return "", errors.Errorf("%s is null", prefix)
+	}
+
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if !v.IsNil() {
+			v = v.Elem()
+		}
+	}
+
+	if primitive := eip712PrimitiveType(t); len(primitive) > 0 {
+		return primitive, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "", errors.Errorf("%s is not an array", prefix)
+		}
+
+		if v.Kind() == reflect.Ptr || v.Len() == 0 || len(items) == 0 {
+			// empty arrays are typed after the Go type of their items
+			itemType, err := w.goType(prefix, t.Elem())
+			if err != nil {
+				return "", err
+			}
+			return itemType + "[]", nil
+		}
+
+		itemType, err := w.fieldType(prefix, v.Index(0), items[0])
+		if err != nil {
+			return "", err
+		}
+
+		return itemType + "[]", nil
+
+	case reflect.Struct:
+		doc, ok := value.(map[string]interface{})
+		if !ok {
+			return "", errors.Errorf("%s is not an object", prefix)
+		}
+
+		typeName := eip712TypeName(prefix)
+		if t == anyType {
+			return typeName, w.walkAny(typeName, prefix, v, doc)
+		}
+
+		if v.Kind() == reflect.Ptr {
+			// nil pointer rendered as an object, use the zero value
+			v = reflect.New(t).Elem()
+		}
+
+		return typeName, w.walkStruct(typeName, prefix, v, doc)
+
+	default:
+		return "", errors.Errorf("%s has unsupported type %s", prefix, t)
+	}
+}
+
+// goType infers the EIP-712 type of a Go type without a value, including all its JSON fields.
+func (w *eip712TypeWalker) goType(prefix string, t reflect.Type) (string, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if primitive := eip712PrimitiveType(t); len(primitive) > 0 {
+		return primitive, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		itemType, err := w.goType(prefix, t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return itemType + "[]", nil
+
+	case reflect.Struct:
+		if t == anyType {
+			return "", errors.Errorf("%s can't be typed without a value", prefix)
+		}
+
+		fields := make([]EIP712Type, 0, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			structField := t.Field(i)
+			if structField.PkgPath != "" {
+				continue
+			}
+
+			name := strings.Split(structField.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+
+			fieldType, err := w.goType(prefix+"."+name, structField.Type)
+			if err != nil {
+				return "", err
+			}
+
+			fields = append(fields, EIP712Type{Name: name, Type: fieldType})
+		}
+
+		typeName := eip712TypeName(prefix)
+		w.types[typeName] = fields
+		return typeName, nil
+
+	default:
+		return "", errors.Errorf("%s has unsupported type %s", prefix, t)
+	}
+}
+
+// walkAny types an Any as its amino JSON encoding: the amino type name and the value.
+func (w *eip712TypeWalker) walkAny(typeName, prefix string, v reflect.Value, doc map[string]interface{}) error {
+	valueDoc, ok := doc["value"].(map[string]interface{})
+	if !ok {
+		return errors.Errorf("%s has no value", prefix)
+	} else if !v.CanAddr() {
+		return errors.Errorf("%s can't be unpacked", prefix)
+	}
+
+	cached := v.Addr().Interface().(*codectypes.Any).GetCachedValue()
+	if cached == nil {
+		return errors.Errorf("%s is not unpacked", prefix)
+	}
+
+	valuePrefix := prefix + ".value"
+	valueType := eip712TypeName(valuePrefix)
+	if err := w.walkStruct(valueType, valuePrefix, reflect.ValueOf(cached), valueDoc); err != nil {
+		return err
+	}
+
+	w.types[typeName] = []EIP712Type{
+		{Name: "type", Type: "string"},
+		{Name: "value", Type: valueType},
+	}
+
+	return nil
+}
+
+// eip712PrimitiveType returns the EIP-712 type of the value as encoded by amino JSON,
+// or an empty string for composite types.
+func eip712PrimitiveType(t reflect.Type) string {
+	for _, stringType := range eip712StringTypes {
+		if t == stringType {
+			return "string"
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int64:
+		return "int64"
+	case reflect.Uint, reflect.Uint64:
+		return "uint64"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return t.Kind().String()
+	case reflect.Slice:
+		// bytes and bech32 addresses are encoded as strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string"
+		}
+	}
+
+	return ""
+}
+
+// eip712TypeName converts a field path into a type name, e.g. _.foo_bar.baz into TypeFooBarBaz.
+func eip712TypeName(prefix string) string {
+	var name strings.Builder
+	for _, part := range strings.Split(prefix, ".") {
+		if part == eip712TypeDefRoot {
+			name.WriteString("Type")
+			continue
+		}
+
+		for _, subpart := range strings.Split(part, "_") {
+			runes := []rune(subpart)
+			if len(runes) == 0 {
+				continue
+			}
+			runes[0] = unicode.ToUpper(runes[0])
+			name.WriteString(string(runes))
+		}
+	}
+
+	return name.String()
+}
@@ -0,0 +1,397 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	govtypes "github.com/cosmos/cosmos-sdk/x/gov/types"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+	chaintypes "github.com/gotabit/sdk-go/chain/types"
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// mailTypedData is the example typed data of the EIP-712 specification.
+func mailTypedData() EIP712TypedData {
+	return EIP712TypedData{
+		Types: EIP712Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Person": {
+				{Name: "name", Type: "string"},
+				{Name: "wallet", Type: "address"},
+			},
+			"Mail": {
+				{Name: "from", Type: "Person"},
+				{Name: "to", Type: "Person"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: EIP712Domain{
+			Name:              "Ether Mail",
+			Version:           "1",
+			ChainID:           1,
+			VerifyingContract: "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC",
+		},
+		Message: map[string]interface{}{
+			"from": map[string]interface{}{
+				"name":   "Cow",
+				"wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826",
+			},
+			"to": map[string]interface{}{
+				"name":   "Bob",
+				"wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB",
+			},
+			"contents": "Hello, Bob!",
+		},
+	}
+}
+
+func TestEIP712SigHashOfSpecExample(t *testing.T) {
+	typedData := mailTypedData()
+
+	if encoded := typedData.encodeType("Mail"); encoded != "Mail(Person from,Person to,string contents)Person(string name,address wallet)" {
+		t.Fatalf("unexpected type encoding %s", encoded)
+	}
+
+	domainSeparator, err := typedData.hashStruct("EIP712Domain", typedData.Domain.message())
+	if err != nil {
+		t.Fatal(err)
+	} else if got := hex.EncodeToString(domainSeparator); got != "f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f" {
+		t.Fatalf("unexpected domain separator %s", got)
+	}
+
+	messageHash, err := typedData.hashStruct("Mail", typedData.Message)
+	if err != nil {
+		t.Fatal(err)
+	} else if got := hex.EncodeToString(messageHash); got != "c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e" {
+		t.Fatalf("unexpected message hash %s", got)
+	}
+
+	sigHash, err := EIP712SigHash(typedData)
+	if err != nil {
+		t.Fatal(err)
+	} else if got := hex.EncodeToString(sigHash); got != "be609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2" {
+		t.Fatalf("unexpected sig hash %s", got)
+	}
+}
+
+func TestEIP712HashStructOfArrays(t *testing.T) {
+	typedData := EIP712TypedData{
+		Types: EIP712Types{
+			"Fee": {
+				{Name: "amount", Type: "Coin[]"},
+				{Name: "gas", Type: "string"},
+			},
+			"Coin": {
+				{Name: "denom", Type: "string"},
+				{Name: "amount", Type: "string"},
+			},
+		},
+	}
+
+	coins := []interface{}{
+		map[string]interface{}{"denom": "aaa", "amount": "1"},
+		map[string]interface{}{"denom": "bbb", "amount": "2"},
+	}
+
+	// types referenced by arrays are dependencies as well
+	if encoded := typedData.encodeType("Fee"); encoded != "Fee(Coin[] amount,string gas)Coin(string denom,string amount)" {
+		t.Fatalf("unexpected type encoding %s", encoded)
+	}
+
+	// arrays of structs are the hash of the concatenated struct hashes
+	var coinHashes []byte
+	for _, coin := range coins {
+		coinHash, err := typedData.hashStruct("Coin", coin.(map[string]interface{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		coinHashes = append(coinHashes, coinHash...)
+	}
+
+	var encoded []byte
+	encoded = append(encoded, crypto.Keccak256([]byte(typedData.encodeType("Fee")))...)
+	encoded = append(encoded, crypto.Keccak256(coinHashes)...)
+	encoded = append(encoded, crypto.Keccak256([]byte("100"))...)
+
+	feeHash, err := typedData.hashStruct("Fee", map[string]interface{}{"amount": coins, "gas": "100"})
+	if err != nil {
+		t.Fatal(err)
+	} else if hex.EncodeToString(feeHash) != hex.EncodeToString(crypto.Keccak256(encoded)) {
+		t.Fatal("unexpected hash of the struct array")
+	}
+
+	if _, err := typedData.hashStruct("Fee", map[string]interface{}{"amount": coins, "gas": 100.0}); err == nil {
+		t.Fatal("expected a number to be rejected as string")
+	}
+}
+
+func TestEIP712Integers(t *testing.T) {
+	for _, tc := range []struct {
+		typeName string
+		value    interface{}
+		valid    bool
+	}{
+		{"uint256", "0x10", true},
+		{"uint256", "16", true},
+		{"uint64", 16.0, true},
+		{"uint64", uint64(1) << 63, true},
+		{"uint8", 256.0, false},
+		{"uint8", -1.0, false},
+		{"int8", -128.0, true},
+		{"int8", 128.0, false},
+		{"uint256", 1.5, false},
+		{"uint7", 1.0, false},
+	} {
+		if _, err := eip712Integer(tc.typeName, tc.value); (err == nil) != tc.valid {
+			t.Errorf("%s %v: expected valid %v, got %v", tc.typeName, tc.value, tc.valid, err)
+		}
+	}
+}
+
+func TestSignEIP712WithChainConfig(t *testing.T) {
+	chainConfig := common.GotabitChainConfig()
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := sdk.AccAddress(privKey.PubKey().Address())
+
+	// the addresses don't use the prefix of the global bech32 config
+	msg := &banktypes.MsgSend{
+		FromAddress: chainConfig.FormatAccAddress(signer),
+		ToAddress:   chainConfig.FormatAccAddress(signer),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("ugtb", 1)),
+	}
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed, Fees: "10ugtb"}, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typedData, err := offlineTx.EIP712TypedData(txConfig, chainConfig, 9000, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	feePayer := typedData.Message["fee"].(map[string]interface{})["feePayer"].(string)
+	if !strings.HasPrefix(feePayer, "gio1") {
+		t.Fatalf("expected the fee payer to be formatted with the chain config, got %s", feePayer)
+	}
+
+	txBytes, err := offlineTx.SignEIP712(txConfig, chainConfig, 9000, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extOpts := decoded.(interface{ GetExtensionOptions() []*codectypes.Any }).GetExtensionOptions()
+	if len(extOpts) != 1 {
+		t.Fatalf("expected the web3 tx extension, got %d extension options", len(extOpts))
+	}
+
+	ext := &chaintypes.ExtensionOptionsWeb3Tx{}
+	if err := ext.Unmarshal(extOpts[0].Value); err != nil {
+		t.Fatal(err)
+	} else if ext.FeePayer != feePayer || ext.TypedDataChainID != 9000 {
+		t.Fatalf("unexpected web3 tx extension %+v", ext)
+	}
+
+	sigHashPreimage, err := eip712SigHashPreimage(typedData)
+	if err != nil {
+		t.Fatal(err)
+	} else if !privKey.PubKey().VerifySignature(sigHashPreimage, ext.FeePayerSig) {
+		t.Fatal("expected the fee payer signature to sign the typed data")
+	}
+}
+
+// coreTypedData converts the typed data into the one of go-ethereum signer/core.
+func coreTypedData(typedData EIP712TypedData) *core.TypedData {
+	types := core.Types{}
+	for name, members := range typedData.Types {
+		types[name] = []core.Type{}
+		for _, member := range members {
+			types[name] = append(types[name], core.Type{Name: member.Name, Type: member.Type})
+		}
+	}
+
+	domain := core.TypedDataDomain{
+		Name:              typedData.Domain.Name,
+		Version:           typedData.Domain.Version,
+		VerifyingContract: typedData.Domain.VerifyingContract,
+		Salt:              typedData.Domain.Salt,
+	}
+	if typedData.Domain.ChainID > 0 {
+		domain.ChainId = math.NewHexOrDecimal256(int64(typedData.Domain.ChainID))
+	}
+
+	return &core.TypedData{
+		Types:       types,
+		PrimaryType: typedData.PrimaryType,
+		Domain:      domain,
+		Message:     typedData.Message,
+	}
+}
+
+// coreHashStruct hashes the struct with the type and primitive encodings of signer/core, composed
+// as in the EIP-712 spec. signer/core v1.9.25 leaves the types referenced by arrays out of the type
+// encoding and doesn't hash the items of struct arrays, so its HashStruct only follows the spec for
+// structs without struct arrays.
+func coreHashStruct(t *testing.T, typedData *core.TypedData, typeName string, data map[string]interface{}) []byte {
+	t.Helper()
+
+	deps := map[string]bool{}
+	var collect func(typeName string)
+	collect = func(typeName string) {
+		typeName = strings.Split(typeName, "[")[0]
+		if _, ok := typedData.Types[typeName]; !ok || deps[typeName] {
+			return
+		}
+		deps[typeName] = true
+		for _, member := range typedData.Types[typeName] {
+			collect(member.Type)
+		}
+	}
+	collect(typeName)
+	delete(deps, typeName)
+
+	names := make([]string, 0, len(deps))
+	for name := range deps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// each struct is encoded on its own, without the types it references
+	var encodedType []byte
+	for _, name := range append([]string{typeName}, names...) {
+		single := &core.TypedData{Types: core.Types{name: typedData.Types[name]}}
+		encodedType = append(encodedType, single.EncodeType(name)...)
+	}
+
+	buf := crypto.Keccak256(encodedType)
+	for _, member := range typedData.Types[typeName] {
+		buf = append(buf, coreEncodeValue(t, typedData, member.Type, data[member.Name])...)
+	}
+
+	return crypto.Keccak256(buf)
+}
+
+func coreEncodeValue(t *testing.T, typedData *core.TypedData, typeName string, value interface{}) []byte {
+	t.Helper()
+
+	if idx := strings.LastIndex(typeName, "["); idx > 0 {
+		var buf []byte
+		for _, item := range value.([]interface{}) {
+			buf = append(buf, coreEncodeValue(t, typedData, typeName[:idx], item)...)
+		}
+		return crypto.Keccak256(buf)
+	} else if _, ok := typedData.Types[typeName]; ok {
+		return coreHashStruct(t, typedData, typeName, value.(map[string]interface{}))
+	}
+
+	encoded, err := typedData.EncodePrimitiveValue(typeName, value, 1)
+	if err != nil {
+		t.Fatalf("signer/core failed to encode %v as %s: %v", value, typeName, err)
+	}
+	return encoded
+}
+
+func TestEIP712HashStructMatchesSignerCore(t *testing.T) {
+	signer := newTestSigner()
+	addr := signer.Address().String()
+	coins := sdk.NewCoins(sdk.NewInt64Coin("aaa", 1), sdk.NewInt64Coin("bbb", 2))
+
+	for name, msg := range map[string]sdk.Msg{
+		// uint64 and int32 values, no arrays
+		"vote": govtypes.NewMsgVote(signer.Address(), 1<<40, govtypes.OptionYes),
+		"send": banktypes.NewMsgSend(signer.Address(), signer.Address(), coins),
+		// the item type of empty arrays is inferred from their Go type
+		"send without amount": banktypes.NewMsgSend(signer.Address(), signer.Address(), sdk.Coins{}),
+		// struct arrays nested in struct arrays, including an empty one
+		"multi send": &banktypes.MsgMultiSend{
+			Inputs: []banktypes.Input{
+				{Address: addr, Coins: coins},
+			},
+			Outputs: []banktypes.Output{
+				{Address: addr, Coins: coins[:1]},
+				{Address: addr, Coins: sdk.Coins{}},
+			},
+		},
+	} {
+		txConfig := newFakeChain().txConfig
+		offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{
+			ChainID:       "test-1",
+			AccountNumber: 1 << 40,
+			Sequence:      3,
+			Gas:           fakeGasUsed,
+			Fees:          "10aaa",
+			TimeoutHeight: 500,
+		}, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typedData, err := offlineTx.EIP712TypedData(txConfig, common.GotabitChainConfig(), 9000, signer.Address())
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		coreData := coreTypedData(typedData)
+
+		for typeName := range typedData.Types {
+			if members := typedData.Types[typeName]; len(members) == 0 {
+				t.Fatalf("%s: %s has no members", name, typeName)
+			}
+		}
+
+		domainSeparator, err := typedData.hashStruct("EIP712Domain", typedData.Domain.message())
+		if err != nil {
+			t.Fatal(err)
+		}
+		coreDomainSeparator, err := coreData.HashStruct("EIP712Domain", coreData.Domain.Map())
+		if err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(domainSeparator, coreDomainSeparator) {
+			t.Fatalf("%s: domain separator %x, signer/core %x", name, domainSeparator, []byte(coreDomainSeparator))
+		}
+
+		txHash, err := typedData.hashStruct("Tx", typedData.Message)
+		if err != nil {
+			t.Fatal(err)
+		} else if coreHash := coreHashStruct(t, coreData, "Tx", typedData.Message); !bytes.Equal(txHash, coreHash) {
+			t.Fatalf("%s: tx hash %x, signer/core %x", name, txHash, coreHash)
+		}
+
+		// msgs without struct arrays are hashed by signer/core on its own
+		msgDoc := typedData.Message["msgs"].([]interface{})[0].(map[string]interface{})
+		if name == "vote" {
+			msgHash, err := typedData.hashStruct("Msg", msgDoc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			coreMsgHash, err := coreData.HashStruct("Msg", msgDoc)
+			if err != nil {
+				t.Fatal(err)
+			} else if !bytes.Equal(msgHash, coreMsgHash) {
+				t.Fatalf("%s: msg hash %x, signer/core %x", name, msgHash, []byte(coreMsgHash))
+			}
+		}
+	}
+}
@@ -0,0 +1,506 @@
+package chain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/InjectiveLabs/suplog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+	tmclient "github.com/gotabit/sdk-go/client/tm"
+)
+
+const (
+	DefaultEndpointMaxBlockLag         = 5
+	DefaultEndpointHealthCheckInterval = 10 * time.Second
+	DefaultEndpointHealthCheckTimeout  = 3 * time.Second
+)
+
+var ErrNoEndpoints = errors.New("endpoint pool has no nodes")
+
+var errNoHealthyEndpoint = status.Error(codes.Unavailable, "no endpoint within the max block lag")
+
+// PoolNode is a node of the EndpointPool.
+type PoolNode struct {
+	// Address is the gRPC endpoint of the node, e.g. "tcp://127.0.0.1:9900"
+	Address string
+	// TLSCert enables secure transport, the node is dialed insecure when nil
+	TLSCert credentials.TransportCredentials
+	// TmClient reports the latest block height of the node. Nodes without it are not
+	// health-checked, they are marked down by Unavailable errors for a health check interval.
+	TmClient tmclient.TendermintClient
+}
+
+// EndpointStatus is the last known health of a pool node.
+type EndpointStatus struct {
+	Address string
+	Height  int64
+	Latency time.Duration
+	Healthy bool
+	Lagging bool
+}
+
+type EndpointPoolOptions struct {
+	MaxBlockLag         int64
+	HealthCheckInterval time.Duration
+	HealthCheckTimeout  time.Duration
+	SessionDir          string
+	RetryPolicy         common.RetryPolicy
+}
+
+type EndpointPoolOption func(opts *EndpointPoolOptions) error
+
+func DefaultEndpointPoolOptions() *EndpointPoolOptions {
+	return &EndpointPoolOptions{
+		MaxBlockLag:         DefaultEndpointMaxBlockLag,
+		HealthCheckInterval: DefaultEndpointHealthCheckInterval,
+		HealthCheckTimeout:  DefaultEndpointHealthCheckTimeout,
+		RetryPolicy:         common.DefaultRetryPolicy(),
+	}
+}
+
+// OptionEndpointMaxBlockLag sets how many blocks a node may be behind the highest node
+// before the pool stops routing calls to it.
+func OptionEndpointMaxBlockLag(blocks int64) EndpointPoolOption {
+	return func(opts *EndpointPoolOptions) error {
+		if blocks < 0 {
+			return errors.Errorf("max block lag must not be negative, got %d", blocks)
+		}
+
+		opts.MaxBlockLag = blocks
+		return nil
+	}
+}
+
+// OptionEndpointHealthCheckInterval sets how often the nodes are health-checked.
+// Zero only checks the nodes once, when the pool is created.
+func OptionEndpointHealthCheckInterval(interval time.Duration) EndpointPoolOption {
+	return func(opts *EndpointPoolOptions) error {
+		if interval < 0 {
+			return errors.Errorf("health check interval must not be negative, got %s", interval)
+		}
+
+		opts.HealthCheckInterval = interval
+		return nil
+	}
+}
+
+// OptionEndpointHealthCheckTimeout sets how long a node has to report its latest height.
+func OptionEndpointHealthCheckTimeout(timeout time.Duration) EndpointPoolOption {
+	return func(opts *EndpointPoolOptions) error {
+		if timeout <= 0 {
+			return errors.Errorf("health check timeout must be positive, got %s", timeout)
+		}
+
+		opts.HealthCheckTimeout = timeout
+		return nil
+	}
+}
+
+// OptionEndpointSessionDir sets the dir the session affinity cookies of the nodes are saved in,
+// so they outlive the pool. Cookies are only kept in memory when dir is empty.
+func OptionEndpointSessionDir(dir string) EndpointPoolOption {
+	return func(opts *EndpointPoolOptions) error {
+		opts.SessionDir = dir
+		return nil
+	}
+}
+
+// OptionEndpointRetryPolicy sets how failed calls are retried on a node, before the pool
+// fails over to the next node on codes.Unavailable.
+func OptionEndpointRetryPolicy(policy common.RetryPolicy) EndpointPoolOption {
+	return func(opts *EndpointPoolOptions) error {
+		if err := policy.Validate(); err != nil {
+			err = errors.Wrap(err, "invalid retry policy")
+			return err
+		}
+
+		opts.RetryPolicy = policy
+		return nil
+	}
+}
+
+type poolNode struct {
+	PoolNode
+	conn *grpc.ClientConn
+
+	height  int64
+	latency time.Duration
+	lagging bool
+	down    bool
+	downAt  time.Time
+}
+
+func (n *poolNode) status() EndpointStatus {
+	return EndpointStatus{
+		Address: n.Address,
+		Height:  n.height,
+		Latency: n.latency,
+		Healthy: !n.down && !n.lagging,
+		Lagging: n.lagging,
+	}
+}
+
+// EndpointPool is a gRPC connection to multiple nodes of the same chain. The nodes are
+// health-checked by their latest block height and latency, and calls are routed to the
+// healthiest node. Nodes more than the max block lag behind the highest node are refused,
+// and calls failing with codes.Unavailable are retried on the next healthiest node.
+type EndpointPool struct {
+	opts   *EndpointPoolOptions
+	logger log.Logger
+	nodes  []*poolNode
+
+	mux       sync.RWMutex
+	closeC    chan struct{}
+	closeOnce sync.Once
+}
+
+var _ grpc.ClientConnInterface = (*EndpointPool)(nil)
+
+// NewEndpointPool dials all nodes and checks their health before it returns.
+func NewEndpointPool(nodes []PoolNode, options ...EndpointPoolOption) (*EndpointPool, error) {
+	if len(nodes) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	opts := DefaultEndpointPoolOptions()
+	for _, opt := range options {
+		if err := opt(opts); err != nil {
+			err = errors.Wrap(err, "error in endpoint pool option")
+			return nil, err
+		}
+	}
+
+	p := &EndpointPool{
+		opts: opts,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "endpointPool",
+		}),
+		nodes:  make([]*poolNode, 0, len(nodes)),
+		closeC: make(chan struct{}),
+	}
+
+	checked := false
+	for _, node := range nodes {
+		conn, err := dialNode(node.Address, node.TLSCert, opts)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+
+		p.nodes = append(p.nodes, &poolNode{
+			PoolNode: node,
+			conn:     conn,
+		})
+		checked = checked || node.TmClient != nil
+	}
+
+	if checked {
+		p.checkHealth()
+
+		if opts.HealthCheckInterval > 0 {
+			go p.runHealthChecks()
+		}
+	}
+
+	return p, nil
+}
+
+// PoolNodesFromNetwork returns the gRPC endpoints of the network as pool nodes, each one
+// health-checked via the Tendermint RPC endpoint at the same index, if there is one.
+func PoolNodesFromNetwork(network common.NetworkConfig) ([]PoolNode, error) {
+	nodes := make([]PoolNode, 0, len(network.GRPC))
+	for i, endpoint := range network.GRPC {
+		tlsCert, err := endpoint.TransportCredentials()
+		if err != nil {
+			return nil, err
+		}
+
+		node := PoolNode{
+			Address: endpoint.Address,
+			TLSCert: tlsCert,
+		}
+		if i < len(network.RPC) {
+			node.TmClient = tmclient.NewRPCClient(network.RPC[i].Address)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func dialNode(protoAddr string, tlsCert credentials.TransportCredentials, opts *EndpointPoolOptions) (*grpc.ClientConn, error) {
+	session := newSessionAffinity(protoAddr, tlsCert != nil, opts.SessionDir)
+	dialOpts := []grpc.DialOption{
+		grpc.WithContextDialer(common.DialerFunc),
+		// every retry attempt carries the latest session cookies
+		grpc.WithChainUnaryInterceptor(RetryUnaryInterceptor(opts.RetryPolicy), session.unaryInterceptor()),
+		grpc.WithChainStreamInterceptor(session.streamInterceptor()),
+	}
+	if tlsCert != nil {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(tlsCert))
+	} else {
+		dialOpts = append(dialOpts, grpc.WithInsecure())
+	}
+
+	conn, err := grpc.Dial(protoAddr, dialOpts...)
+	if err != nil {
+		err := errors.Wrapf(err, "failed to connect to the gRPC: %s", protoAddr)
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+// Invoke performs a unary RPC on the healthiest node, failing over to the next node on codes.Unavailable.
+func (p *EndpointPool) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	err := errNoHealthyEndpoint
+	for _, node := range p.candidates() {
+		err = node.conn.Invoke(ctx, method, args, reply, opts...)
+		if status.Code(err) != codes.Unavailable {
+			p.markUp(node)
+			return err
+		} else if ctx.Err() != nil {
+			return err
+		}
+
+		p.markDown(node, err)
+	}
+
+	return err
+}
+
+// NewStream opens a stream on the healthiest node, failing over to the next node on codes.Unavailable.
+// Streams that are already open are not moved to other nodes.
+func (p *EndpointPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	err := errNoHealthyEndpoint
+	for _, node := range p.candidates() {
+		var stream grpc.ClientStream
+		stream, err = node.conn.NewStream(ctx, desc, method, opts...)
+		if status.Code(err) != codes.Unavailable {
+			p.markUp(node)
+			return stream, err
+		} else if ctx.Err() != nil {
+			return stream, err
+		}
+
+		p.markDown(node, err)
+	}
+
+	return nil, err
+}
+
+// Conn returns the connection of the healthiest node.
+func (p *EndpointPool) Conn() *grpc.ClientConn {
+	if nodes := p.candidates(); len(nodes) > 0 {
+		return nodes[0].conn
+	}
+
+	return p.nodes[0].conn
+}
+
+// Status returns the last known health of all nodes, in the order they were added.
+func (p *EndpointPool) Status() []EndpointStatus {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	res := make([]EndpointStatus, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		res = append(res, node.status())
+	}
+
+	return res
+}
+
+// Close stops the health checks and closes the connections of all nodes.
+func (p *EndpointPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closeC)
+
+		for _, node := range p.nodes {
+			node.conn.Close()
+		}
+	})
+}
+
+// candidates returns the nodes calls are routed to, healthiest first. When all nodes
+// are down, the ones that aren't behind are retried, nodes that lag are never used.
+func (p *EndpointPool) candidates() []*poolNode {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+
+	now := time.Now()
+	nodes := make([]*poolNode, 0, len(p.nodes))
+	for _, node := range p.nodes {
+		if !p.isDown(node, now) && !node.lagging {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		for _, node := range p.nodes {
+			if !node.lagging {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		// nodes that are not health-checked have no known latency
+		if checkedA, checkedB := a.TmClient != nil, b.TmClient != nil; checkedA != checkedB {
+			return checkedA
+		} else if a.latency != b.latency {
+			return a.latency < b.latency
+		}
+
+		return a.height > b.height
+	})
+
+	return nodes
+}
+
+// isDown reports whether calls should skip the node. Nodes without health checks are
+// given another chance once a health check interval has passed since they went down.
+// Must be called with mux held.
+func (p *EndpointPool) isDown(node *poolNode, now time.Time) bool {
+	if !node.down {
+		return false
+	} else if node.TmClient != nil {
+		return true
+	}
+
+	cooldown := p.opts.HealthCheckInterval
+	if cooldown <= 0 {
+		cooldown = DefaultEndpointHealthCheckInterval
+	}
+
+	return now.Sub(node.downAt) < cooldown
+}
+
+func (p *EndpointPool) markDown(node *poolNode, err error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if !node.down {
+		p.logger.WithError(err).Warningln("endpoint is unavailable, failing over:", node.Address)
+	}
+	node.down = true
+	node.downAt = time.Now()
+}
+
+// markUp brings a node that was marked down back into rotation after it has served a call.
+func (p *EndpointPool) markUp(node *poolNode) {
+	p.mux.RLock()
+	down := node.down
+	p.mux.RUnlock()
+
+	if !down {
+		return
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if node.down {
+		p.logger.Infoln("endpoint is available again:", node.Address)
+	}
+	node.down = false
+}
+
+func (p *EndpointPool) runHealthChecks() {
+	t := time.NewTicker(p.opts.HealthCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-p.closeC:
+			return
+		case <-t.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// checkHealth queries the latest block height of all nodes concurrently, the latency of
+// the query is used to rank the nodes that are within the max block lag of the highest node.
+func (p *EndpointPool) checkHealth() {
+	type result struct {
+		height  int64
+		latency time.Duration
+		err     error
+	}
+
+	results := make([]result, len(p.nodes))
+	wg := new(sync.WaitGroup)
+	for i, node := range p.nodes {
+		if node.TmClient == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, node *poolNode) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), p.opts.HealthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			height, err := node.TmClient.GetLatestBlockHeight(ctx)
+			results[i] = result{
+				height:  height,
+				latency: time.Since(start),
+				err:     err,
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	var maxHeight int64
+	for i, node := range p.nodes {
+		if node.TmClient != nil && results[i].err == nil && results[i].height > maxHeight {
+			maxHeight = results[i].height
+		}
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for i, node := range p.nodes {
+		wasHealthy := node.status().Healthy
+
+		if node.TmClient == nil {
+			// nodes without health checks recover by their cooldown, see isDown
+			continue
+		} else if err := results[i].err; err != nil {
+			if !node.down {
+				node.downAt = time.Now()
+			}
+			node.down = true
+			if wasHealthy {
+				p.logger.WithError(err).Warningln("endpoint health check failed:", node.Address)
+			}
+		} else {
+			node.down = false
+			node.height = results[i].height
+			node.latency = results[i].latency
+			node.lagging = maxHeight-node.height > p.opts.MaxBlockLag
+			if node.lagging && wasHealthy {
+				p.logger.Warningf("endpoint %s is %d blocks behind, refusing it", node.Address, maxHeight-node.height)
+			}
+		}
+
+		if !wasHealthy && node.status().Healthy {
+			p.logger.Infoln("endpoint is healthy again:", node.Address)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func noRetryPolicy() common.RetryPolicy {
+	policy := common.DefaultRetryPolicy()
+	policy.MaxAttempts = 1
+	return policy
+}
+
+func newTestEndpointPool(t *testing.T, nodes []PoolNode, options ...EndpointPoolOption) *EndpointPool {
+	t.Helper()
+
+	options = append([]EndpointPoolOption{
+		OptionEndpointHealthCheckInterval(0),
+		OptionEndpointRetryPolicy(noRetryPolicy()),
+	}, options...)
+
+	pool, err := NewEndpointPool(nodes, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func TestEndpointPoolRefusesLaggingNode(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+	b := startFakeNode(t, "bbb", nil)
+
+	pool := newTestEndpointPool(t, []PoolNode{
+		{Address: a.addr, TmClient: &fakeTmClient{height: 90}},
+		{Address: b.addr, TmClient: &fakeTmClient{height: 100}},
+	}, OptionEndpointMaxBlockLag(5))
+
+	for i := 0; i < 3; i++ {
+		if denom := servedBy(t, pool); denom != "bbb" {
+			t.Fatalf("expected the call to be served by the highest node, got %s", denom)
+		}
+	}
+
+	status := pool.Status()
+	if !status[0].Lagging || status[0].Healthy {
+		t.Errorf("expected the first node to be lagging, got %+v", status[0])
+	} else if !status[1].Healthy {
+		t.Errorf("expected the second node to be healthy, got %+v", status[1])
+	}
+}
+
+func TestEndpointPoolFailsOverOnUnavailable(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+	b := startFakeNode(t, "bbb", nil)
+
+	pool := newTestEndpointPool(t, []PoolNode{
+		{Address: a.addr},
+		{Address: b.addr},
+	})
+
+	if denom := servedBy(t, pool); denom != "aaa" {
+		t.Fatalf("expected the first node to serve the call, got %s", denom)
+	}
+
+	a.server.Stop()
+	if denom := servedBy(t, pool); denom != "bbb" {
+		t.Fatalf("expected the call to fail over to the second node, got %s", denom)
+	}
+
+	if status := pool.Status(); status[0].Healthy {
+		t.Errorf("expected the stopped node to be marked down, got %+v", status[0])
+	}
+}
+
+func TestEndpointPoolRecoversUncheckedNode(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+	b := startFakeNode(t, "bbb", nil)
+
+	cooldown := 100 * time.Millisecond
+	pool := newTestEndpointPool(t, []PoolNode{
+		{Address: a.addr},
+		{Address: b.addr},
+	}, OptionEndpointHealthCheckInterval(cooldown))
+
+	a.setUnavailable(true)
+	if denom := servedBy(t, pool); denom != "bbb" {
+		t.Fatalf("expected the call to fail over to the second node, got %s", denom)
+	}
+
+	// the node is skipped during its cooldown
+	a.setUnavailable(false)
+	if denom := servedBy(t, pool); denom != "bbb" {
+		t.Fatalf("expected the down node to be skipped, got %s", denom)
+	}
+
+	time.Sleep(cooldown)
+	if denom := servedBy(t, pool); denom != "aaa" {
+		t.Fatalf("expected the node to be back in rotation after its cooldown, got %s", denom)
+	}
+
+	if status := pool.Status(); !status[0].Healthy {
+		t.Errorf("expected the recovered node to be healthy, got %+v", status[0])
+	}
+}
+
+func TestEndpointPoolRetriesDownNodesWhenAllAreDown(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+	b := startFakeNode(t, "bbb", nil)
+
+	pool := newTestEndpointPool(t, []PoolNode{
+		{Address: a.addr, TmClient: &fakeTmClient{err: errors.New("rpc is down")}},
+		{Address: b.addr, TmClient: &fakeTmClient{err: errors.New("rpc is down")}},
+	})
+
+	for _, s := range pool.Status() {
+		if s.Healthy {
+			t.Fatalf("expected nodes failing health checks to be down, got %+v", s)
+		}
+	}
+
+	// gRPC may still serve calls when the Tendermint RPC of the nodes fails
+	if denom := servedBy(t, pool); denom != "aaa" && denom != "bbb" {
+		t.Fatalf("unexpected denom %s", denom)
+	}
+}
+
+func TestEndpointPoolNoHealthyEndpoint(t *testing.T) {
+	a := startFakeNode(t, "aaa", nil)
+	b := startFakeNode(t, "bbb", nil)
+	a.setUnavailable(true)
+	b.setUnavailable(true)
+
+	pool := newTestEndpointPool(t, []PoolNode{
+		{Address: a.addr},
+		{Address: b.addr},
+	})
+
+	_, err := banktypes.NewQueryClient(pool).Balance(context.Background(), &banktypes.QueryBalanceRequest{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected codes.Unavailable when all nodes fail, got %v", err)
+	}
+
+	if a.callCount() != 1 || b.callCount() != 1 {
+		t.Errorf("expected every node to be tried once, got %d and %d calls", a.callCount(), b.callCount())
+	}
+}
+
+func TestNewEndpointPoolWithoutNodes(t *testing.T) {
+	if _, err := NewEndpointPool(nil); err != ErrNoEndpoints {
+		t.Fatalf("expected ErrNoEndpoints, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package chain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrSequenceMismatch is returned when a Tx has been signed with a sequence
+// different from the one of the account on chain.
+type ErrSequenceMismatch struct {
+	Expected uint64
+	Got      uint64
+}
+
+func (e *ErrSequenceMismatch) Error() string {
+	return fmt.Sprintf("account sequence mismatch, expected %d, got %d", e.Expected, e.Got)
+}
+
+// ErrInsufficientFee is returned when the Tx fee is below the node minimum gas prices.
+type ErrInsufficientFee struct {
+	Got      string
+	Required string
+}
+
+func (e *ErrInsufficientFee) Error() string {
+	return fmt.Sprintf("insufficient fees; got: %s required: %s", e.Got, e.Required)
+}
+
+// ErrOutOfGas is returned when the Tx used more gas than it provided.
+type ErrOutOfGas struct {
+	Wanted int64
+	Used   int64
+}
+
+func (e *ErrOutOfGas) Error() string {
+	return fmt.Sprintf("out of gas; gasWanted: %d, gasUsed: %d", e.Wanted, e.Used)
+}
+
+// ErrMempoolFull is returned when the node mempool can't accept more Txs.
+type ErrMempoolFull struct {
+	Log string
+}
+
+func (e *ErrMempoolFull) Error() string {
+	return fmt.Sprintf("mempool is full: %s", e.Log)
+}
+
+// ErrTxInMempool is returned when exactly the same Tx has already been accepted by the node.
+type ErrTxInMempool struct {
+	Log string
+}
+
+func (e *ErrTxInMempool) Error() string {
+	return fmt.Sprintf("tx already in mempool: %s", e.Log)
+}
+
+// ErrUnauthorized is returned when the signer isn't allowed to execute a message of the Tx.
+type ErrUnauthorized struct {
+	Log string
+}
+
+func (e *ErrUnauthorized) Error() string {
+	return fmt.Sprintf("unauthorized: %s", e.Log)
+}
+
+// ErrTxFailed is returned for any other non-zero Tx response code.
+type ErrTxFailed struct {
+	TxHash    string
+	Codespace string
+	Code      uint32
+	Log       string
+}
+
+func (e *ErrTxFailed) Error() string {
+	return fmt.Sprintf("error %d (%s): %s", e.Code, e.Codespace, e.Log)
+}
+
+var (
+	sequenceMismatchRe = regexp.MustCompile(`account sequence mismatch, expected (\d+), got (\d+)`)
+	insufficientFeeRe  = regexp.MustCompile(`insufficient fees; got: (\S*) required: ([^\s:]*)`)
+	outOfGasRe         = regexp.MustCompile(`gasWanted: (\d+), gasUsed: (\d+)`)
+)
+
+// ParseTxError converts a Tx response with non-zero code into a typed error.
+// Returns nil when the Tx succeeded.
+func ParseTxError(res *sdk.TxResponse) error {
+	if res == nil || res.Code == 0 {
+		return nil
+	}
+
+	if res.Codespace == sdkerrors.RootCodespace {
+		switch res.Code {
+		case sdkerrors.ErrWrongSequence.ABCICode():
+			return parseSequenceMismatch(res.RawLog)
+		case sdkerrors.ErrInsufficientFee.ABCICode():
+			return parseInsufficientFee(res.RawLog)
+		case sdkerrors.ErrOutOfGas.ABCICode():
+			return &ErrOutOfGas{
+				Wanted: res.GasWanted,
+				Used:   res.GasUsed,
+			}
+		case sdkerrors.ErrMempoolIsFull.ABCICode():
+			return &ErrMempoolFull{Log: res.RawLog}
+		case sdkerrors.ErrTxInMempoolCache.ABCICode():
+			return &ErrTxInMempool{Log: res.RawLog}
+		case sdkerrors.ErrUnauthorized.ABCICode():
+			return &ErrUnauthorized{Log: res.RawLog}
+		}
+	}
+
+	return &ErrTxFailed{
+		TxHash:    res.TxHash,
+		Codespace: res.Codespace,
+		Code:      res.Code,
+		Log:       res.RawLog,
+	}
+}
+
+// ParseGRPCError converts the error of a simulate or broadcast gRPC call into a typed error,
+// when it's one of the known failures. Other errors are returned as is.
+func ParseGRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	if st, ok := status.FromError(errors.Cause(err)); ok {
+		msg = st.Message()
+		if st.Code() == codes.ResourceExhausted {
+			return &ErrMempoolFull{Log: msg}
+		}
+	}
+
+	switch {
+	case strings.Contains(msg, "account sequence mismatch"):
+		return parseSequenceMismatch(msg)
+	case strings.Contains(msg, sdkerrors.ErrInsufficientFee.Error()):
+		return parseInsufficientFee(msg)
+	case strings.Contains(msg, sdkerrors.ErrOutOfGas.Error()):
+		return parseOutOfGas(msg)
+	case strings.Contains(msg, sdkerrors.ErrMempoolIsFull.Error()):
+		return &ErrMempoolFull{Log: msg}
+	case strings.Contains(msg, sdkerrors.ErrTxInMempoolCache.Error()),
+		strings.Contains(msg, "tx already exists in cache"):
+		return &ErrTxInMempool{Log: msg}
+	case strings.Contains(msg, sdkerrors.ErrUnauthorized.Error()):
+		return &ErrUnauthorized{Log: msg}
+	default:
+		return err
+	}
+}
+
+func parseSequenceMismatch(log string) error {
+	e := &ErrSequenceMismatch{}
+	if m := sequenceMismatchRe.FindStringSubmatch(log); len(m) == 3 {
+		e.Expected, _ = strconv.ParseUint(m[1], 10, 64)
+		e.Got, _ = strconv.ParseUint(m[2], 10, 64)
+	}
+
+	return e
+}
+
+func parseInsufficientFee(log string) error {
+	e := &ErrInsufficientFee{}
+	if m := insufficientFeeRe.FindStringSubmatch(log); len(m) == 3 {
+		e.Got = m[1]
+		e.Required = m[2]
+	}
+
+	return e
+}
+
+func parseOutOfGas(log string) error {
+	e := &ErrOutOfGas{}
+	if m := outOfGasRe.FindStringSubmatch(log); len(m) == 3 {
+		e.Wanted, _ = strconv.ParseInt(m[1], 10, 64)
+		e.Used, _ = strconv.ParseInt(m[2], 10, 64)
+	}
+
+	return e
+}
+
+func isSequenceMismatch(res *txtypes.BroadcastTxResponse, err error) bool {
+	if err == nil {
+		err = ParseTxError(res.GetTxResponse())
+	}
+
+	var seqErr *ErrSequenceMismatch
+	return errors.As(err, &seqErr)
+}
+
+// isSequenceConsumed reports whether the Tx used up the account sequence, which
+// happens once it passed CheckTx, even if it failed later in DeliverTx.
+func isSequenceConsumed(res *sdk.TxResponse) bool {
+	return res.Code == 0 || res.Height > 0
+}
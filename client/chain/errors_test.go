@@ -0,0 +1,124 @@
+package chain
+
+import (
+	"reflect"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestTxResponse(err *sdkerrors.Error, log string) *sdk.TxResponse {
+	return &sdk.TxResponse{
+		TxHash:    "AB",
+		Codespace: err.Codespace(),
+		Code:      err.ABCICode(),
+		RawLog:    log,
+		GasWanted: 10,
+		GasUsed:   20,
+	}
+}
+
+func TestParseTxError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		res      *sdk.TxResponse
+		expected error
+	}{
+		"sequence mismatch": {
+			res:      newTestTxResponse(sdkerrors.ErrWrongSequence, "account sequence mismatch, expected 5, got 3: incorrect account sequence"),
+			expected: &ErrSequenceMismatch{Expected: 5, Got: 3},
+		},
+		"insufficient fee": {
+			res:      newTestTxResponse(sdkerrors.ErrInsufficientFee, "insufficient fees; got: 1inj required: 2inj: insufficient fee"),
+			expected: &ErrInsufficientFee{Got: "1inj", Required: "2inj"},
+		},
+		"out of gas": {
+			res:      newTestTxResponse(sdkerrors.ErrOutOfGas, "out of gas"),
+			expected: &ErrOutOfGas{Wanted: 10, Used: 20},
+		},
+		"mempool full": {
+			res:      newTestTxResponse(sdkerrors.ErrMempoolIsFull, "mempool is full"),
+			expected: &ErrMempoolFull{Log: "mempool is full"},
+		},
+		"tx in mempool": {
+			res:      newTestTxResponse(sdkerrors.ErrTxInMempoolCache, "tx already exists in cache"),
+			expected: &ErrTxInMempool{Log: "tx already exists in cache"},
+		},
+		"unauthorized": {
+			res:      newTestTxResponse(sdkerrors.ErrUnauthorized, "unauthorized"),
+			expected: &ErrUnauthorized{Log: "unauthorized"},
+		},
+		"other codespace": {
+			res:      &sdk.TxResponse{TxHash: "AB", Codespace: "bank", Code: 5, RawLog: "insufficient funds"},
+			expected: &ErrTxFailed{TxHash: "AB", Codespace: "bank", Code: 5, Log: "insufficient funds"},
+		},
+		"success": {
+			res: &sdk.TxResponse{TxHash: "AB"},
+		},
+		"no response": {},
+	} {
+		if err := ParseTxError(tc.res); !reflect.DeepEqual(err, tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", name, tc.expected, err)
+		}
+	}
+}
+
+func TestParseGRPCError(t *testing.T) {
+	otherErr := status.Error(codes.Internal, "panic")
+
+	for name, tc := range map[string]struct {
+		err      error
+		expected error
+	}{
+		"sequence mismatch": {
+			err:      status.Error(codes.Unknown, "account sequence mismatch, expected 5, got 3: incorrect account sequence"),
+			expected: &ErrSequenceMismatch{Expected: 5, Got: 3},
+		},
+		"wrapped out of gas": {
+			err:      errors.Wrap(status.Error(codes.Unknown, "out of gas in location: WriteFlat; gasWanted: 10, gasUsed: 20: out of gas"), "simulate"),
+			expected: &ErrOutOfGas{Wanted: 10, Used: 20},
+		},
+		"resource exhausted": {
+			err:      status.Error(codes.ResourceExhausted, "too many txs"),
+			expected: &ErrMempoolFull{Log: "too many txs"},
+		},
+		"tx in cache": {
+			err:      status.Error(codes.Unknown, "tx already exists in cache"),
+			expected: &ErrTxInMempool{Log: "tx already exists in cache"},
+		},
+		"other": {
+			err:      otherErr,
+			expected: otherErr,
+		},
+		"nil": {},
+	} {
+		if err := ParseGRPCError(tc.err); !reflect.DeepEqual(err, tc.expected) {
+			t.Errorf("%s: expected %#v, got %#v", name, tc.expected, err)
+		}
+	}
+}
+
+func TestIsSequenceMismatch(t *testing.T) {
+	res := &txtypes.BroadcastTxResponse{TxResponse: newTestTxResponse(sdkerrors.ErrWrongSequence, "account sequence mismatch, expected 5, got 3")}
+	if !isSequenceMismatch(res, nil) {
+		t.Fatal("expected the response to be a sequence mismatch")
+	} else if !isSequenceMismatch(nil, errors.Wrap(&ErrSequenceMismatch{}, "broadcast")) {
+		t.Fatal("expected the wrapped error to be a sequence mismatch")
+	} else if isSequenceMismatch(&txtypes.BroadcastTxResponse{TxResponse: &sdk.TxResponse{}}, nil) {
+		t.Fatal("expected a successful response not to be a sequence mismatch")
+	}
+}
+
+func TestIsSequenceConsumed(t *testing.T) {
+	if !isSequenceConsumed(&sdk.TxResponse{}) {
+		t.Fatal("expected a tx that passed CheckTx to consume the sequence")
+	} else if !isSequenceConsumed(&sdk.TxResponse{Code: 11, Height: 5}) {
+		t.Fatal("expected a tx that failed in a block to consume the sequence")
+	} else if isSequenceConsumed(newTestTxResponse(sdkerrors.ErrWrongSequence, "")) {
+		t.Fatal("expected a tx rejected by CheckTx not to consume the sequence")
+	}
+}
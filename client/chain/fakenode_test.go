@@ -0,0 +1,466 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/grpc/tmservice"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+	tmclient "github.com/gotabit/sdk-go/client/tm"
+)
+
+const fakeGasUsed = 100000
+
+// fakeChain is the state shared by the fake nodes of a chain. Every queried address has an
+// account, Txs are checked for their sequence and timeout height when broadcast, and
+// included in the next block when the test commits one, or right away with autoCommit.
+// Each message uses fakeGasUsed, unless deliverGas changes the gas used by included Txs,
+// which simulations don't see. Txs using more gas than they provide fail with out of gas.
+type fakeChain struct {
+	txConfig client.TxConfig
+
+	mux        sync.Mutex
+	height     int64
+	autoCommit bool
+	onCommit   func(height int64, included []*fakeBroadcast)
+	deliverGas func(msgs int) uint64
+	accounts   map[string]*fakeAccount
+	txs        map[string]*sdk.TxResponse
+	mempool    []*fakeBroadcast
+	broadcasts []*fakeBroadcast
+}
+
+type fakeAccount struct {
+	num      uint64
+	seq      uint64 // sequence of the committed state, the one queries return
+	checkSeq uint64 // sequence of the mempool state, the one broadcasts are checked against
+}
+
+type fakeBroadcast struct {
+	txBytes       []byte
+	hash          string
+	account       *fakeAccount
+	sequence      uint64
+	timeoutHeight uint64
+	gasWanted     uint64
+	msgs          int
+	code          uint32
+}
+
+func newFakeChain() *fakeChain {
+	return &fakeChain{
+		txConfig: NewTxConfig([]signingtypes.SignMode{signingtypes.SignMode_SIGN_MODE_DIRECT}),
+		height:   100,
+		accounts: make(map[string]*fakeAccount),
+		txs:      make(map[string]*sdk.TxResponse),
+	}
+}
+
+// accountLocked returns the account of the address, creating it on first use.
+func (c *fakeChain) accountLocked(addr sdk.AccAddress) *fakeAccount {
+	acc, ok := c.accounts[string(addr)]
+	if !ok {
+		acc = &fakeAccount{num: uint64(7 + len(c.accounts))}
+		c.accounts[string(addr)] = acc
+	}
+
+	return acc
+}
+
+func (c *fakeChain) account(addr sdk.AccAddress) fakeAccount {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return *c.accountLocked(addr)
+}
+
+// commit includes all Txs of the mempool in a new block.
+func (c *fakeChain) commit() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.commitLocked()
+}
+
+func (c *fakeChain) commitLocked() {
+	c.height++
+	for _, pending := range c.mempool {
+		res := c.txs[pending.hash]
+		res.Height = c.height
+		res.GasUsed = int64(c.deliveredGasLocked(pending.msgs))
+		if uint64(res.GasUsed) > pending.gasWanted {
+			res.Codespace, res.Code = sdkerrors.ErrOutOfGas.Codespace(), sdkerrors.ErrOutOfGas.ABCICode()
+			res.RawLog = fmt.Sprintf("out of gas in location: txSize; gasWanted: %d, gasUsed: %d: out of gas", res.GasWanted, res.GasUsed)
+		}
+		pending.account.seq++
+	}
+
+	if c.onCommit != nil {
+		c.onCommit(c.height, c.mempool)
+	}
+	c.mempool = nil
+}
+
+// deliveredGasLocked returns the gas an included Tx with the number of msgs uses.
+func (c *fakeChain) deliveredGasLocked(msgs int) uint64 {
+	if c.deliverGas != nil {
+		return c.deliverGas(msgs)
+	}
+
+	return uint64(msgs) * fakeGasUsed
+}
+
+// dropMempool evicts all pending Txs, like a node restart, resetting the mempool sequence.
+func (c *fakeChain) dropMempool() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	for _, pending := range c.mempool {
+		delete(c.txs, pending.hash)
+		pending.account.checkSeq = pending.account.seq
+	}
+	c.mempool = nil
+}
+
+func (c *fakeChain) setAutoCommit(autoCommit bool) {
+	c.mux.Lock()
+	c.autoCommit = autoCommit
+	c.mux.Unlock()
+}
+
+func (c *fakeChain) latestHeight() int64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.height
+}
+
+func (c *fakeChain) sentBroadcasts() []*fakeBroadcast {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return append([]*fakeBroadcast(nil), c.broadcasts...)
+}
+
+func (c *fakeChain) checkTx(txBytes []byte) *sdk.TxResponse {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	hash := fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+	res := &sdk.TxResponse{TxHash: hash}
+	reject := func(err *sdkerrors.Error, log string) *sdk.TxResponse {
+		res.Codespace, res.Code, res.RawLog = err.Codespace(), err.ABCICode(), log
+		return res
+	}
+
+	decoded, err := c.txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return reject(sdkerrors.ErrTxDecode, err.Error())
+	}
+
+	sigTx := decoded.(authsigning.SigVerifiableTx)
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil || len(sigs) == 0 {
+		return reject(sdkerrors.ErrNoSignatures, "no signatures")
+	}
+
+	acc := c.accountLocked(sigTx.GetSigners()[0])
+	broadcast := &fakeBroadcast{
+		txBytes:       txBytes,
+		hash:          hash,
+		account:       acc,
+		sequence:      sigs[0].Sequence,
+		timeoutHeight: decoded.(sdk.TxWithTimeoutHeight).GetTimeoutHeight(),
+		gasWanted:     decoded.(sdk.FeeTx).GetGas(),
+		msgs:          len(decoded.GetMsgs()),
+	}
+	defer func() {
+		broadcast.code = res.Code
+		c.broadcasts = append(c.broadcasts, broadcast)
+	}()
+
+	if _, ok := c.txs[hash]; ok {
+		return reject(sdkerrors.ErrTxInMempoolCache, "tx already exists in cache")
+	} else if broadcast.sequence != acc.checkSeq {
+		return reject(sdkerrors.ErrWrongSequence, fmt.Sprintf("account sequence mismatch, expected %d, got %d: incorrect account sequence", acc.checkSeq, broadcast.sequence))
+	} else if broadcast.timeoutHeight > 0 && broadcast.timeoutHeight <= uint64(c.height) {
+		return reject(sdkerrors.ErrTxTimeoutHeight, fmt.Sprintf("block height: %d, timeout height: %d: tx timeout height", c.height, broadcast.timeoutHeight))
+	}
+
+	acc.checkSeq++
+	c.txs[hash] = &sdk.TxResponse{TxHash: hash, GasWanted: int64(broadcast.gasWanted)}
+	c.mempool = append(c.mempool, broadcast)
+	if c.autoCommit {
+		c.commitLocked()
+	}
+
+	return res
+}
+
+func (c *fakeChain) getTx(hash string) *sdk.TxResponse {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if res, ok := c.txs[hash]; ok && res.Height > 0 {
+		resCopy := *res
+		return &resCopy
+	}
+
+	return nil
+}
+
+// fakeNode is a local gRPC stand-in for a chain node. Its bank balances are all of its denom,
+// so tests can tell which node served a call.
+type fakeNode struct {
+	banktypes.UnimplementedQueryServer
+
+	chain  *fakeChain
+	denom  string
+	addr   string
+	server *grpc.Server
+
+	unavailable int32
+	calls       int64
+}
+
+func startFakeNode(t *testing.T, denom string, chain *fakeChain) *fakeNode {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chain == nil {
+		chain = newFakeChain()
+	}
+
+	n := &fakeNode{
+		chain: chain,
+		denom: denom,
+		addr:  "tcp://" + lis.Addr().String(),
+	}
+	n.server = grpc.NewServer(grpc.UnaryInterceptor(n.intercept))
+	banktypes.RegisterQueryServer(n.server, n)
+	authtypes.RegisterQueryServer(n.server, &fakeAuthServer{node: n})
+	txtypes.RegisterServiceServer(n.server, &fakeTxServer{node: n})
+	tmservice.RegisterServiceServer(n.server, &fakeTmServer{node: n})
+
+	go n.server.Serve(lis)
+	t.Cleanup(n.server.Stop)
+
+	return n
+}
+
+// setUnavailable makes the node fail all calls with codes.Unavailable while its server keeps running.
+func (n *fakeNode) setUnavailable(unavailable bool) {
+	var v int32
+	if unavailable {
+		v = 1
+	}
+	atomic.StoreInt32(&n.unavailable, v)
+}
+
+func (n *fakeNode) callCount() int64 {
+	return atomic.LoadInt64(&n.calls)
+}
+
+// intercept counts the calls, fails them while the node is unavailable and sets
+// the block height header like the cosmos-sdk gRPC server does.
+func (n *fakeNode) intercept(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	atomic.AddInt64(&n.calls, 1)
+	if atomic.LoadInt32(&n.unavailable) == 1 {
+		return nil, status.Error(codes.Unavailable, "node is unavailable")
+	}
+
+	height := strconv.FormatInt(n.chain.latestHeight(), 10)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(grpctypes.GRPCBlockHeightHeader, height))
+
+	return handler(ctx, req)
+}
+
+func (n *fakeNode) Balance(ctx context.Context, req *banktypes.QueryBalanceRequest) (*banktypes.QueryBalanceResponse, error) {
+	coin := sdk.NewInt64Coin(n.denom, 1)
+	return &banktypes.QueryBalanceResponse{Balance: &coin}, nil
+}
+
+type fakeAuthServer struct {
+	authtypes.UnimplementedQueryServer
+	node *fakeNode
+}
+
+func (s *fakeAuthServer) Account(ctx context.Context, req *authtypes.QueryAccountRequest) (*authtypes.QueryAccountResponse, error) {
+	_, addr, err := bech32.DecodeAndConvert(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	acc := s.node.chain.account(addr)
+	any, err := codectypes.NewAnyWithValue(&authtypes.BaseAccount{
+		Address:       req.Address,
+		AccountNumber: acc.num,
+		Sequence:      acc.seq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &authtypes.QueryAccountResponse{Account: any}, nil
+}
+
+type fakeTxServer struct {
+	txtypes.UnimplementedServiceServer
+	node *fakeNode
+}
+
+func (s *fakeTxServer) Simulate(ctx context.Context, req *txtypes.SimulateRequest) (*txtypes.SimulateResponse, error) {
+	decoded, err := s.node.chain.txConfig.TxDecoder()(req.TxBytes)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &txtypes.SimulateResponse{
+		GasInfo: &sdk.GasInfo{GasUsed: uint64(len(decoded.GetMsgs())) * fakeGasUsed},
+		Result:  &sdk.Result{},
+	}, nil
+}
+
+func (s *fakeTxServer) BroadcastTx(ctx context.Context, req *txtypes.BroadcastTxRequest) (*txtypes.BroadcastTxResponse, error) {
+	return &txtypes.BroadcastTxResponse{TxResponse: s.node.chain.checkTx(req.TxBytes)}, nil
+}
+
+func (s *fakeTxServer) GetTx(ctx context.Context, req *txtypes.GetTxRequest) (*txtypes.GetTxResponse, error) {
+	if res := s.node.chain.getTx(req.Hash); res != nil {
+		return &txtypes.GetTxResponse{TxResponse: res}, nil
+	}
+
+	return nil, status.Errorf(codes.NotFound, "tx not found: %s", req.Hash)
+}
+
+type fakeTmServer struct {
+	tmservice.UnimplementedServiceServer
+	node *fakeNode
+}
+
+func (s *fakeTmServer) GetLatestBlock(ctx context.Context, req *tmservice.GetLatestBlockRequest) (*tmservice.GetLatestBlockResponse, error) {
+	return &tmservice.GetLatestBlockResponse{
+		Block: &tmproto.Block{
+			Header: tmproto.Header{Height: s.node.chain.latestHeight()},
+		},
+	}, nil
+}
+
+// fakeTmClient reports a fixed latest block height, or fails when err is set.
+type fakeTmClient struct {
+	tmclient.TendermintClient
+
+	height int64
+	err    error
+}
+
+func (c *fakeTmClient) GetLatestBlockHeight(ctx context.Context) (int64, error) {
+	return c.height, c.err
+}
+
+// servedBy returns the denom of the node that served a balance query via conn.
+func servedBy(t *testing.T, conn grpc.ClientConnInterface) string {
+	t.Helper()
+
+	res, err := banktypes.NewQueryClient(conn).Balance(context.Background(), &banktypes.QueryBalanceRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return res.Balance.Denom
+}
+
+// newTestClientContext returns a client context without keyring and Tendermint RPC client,
+// so all queries and broadcasts of chain clients go through their gRPC endpoints.
+func newTestClientContext(t *testing.T) client.Context {
+	t.Helper()
+
+	clientCtx, err := NewClientContext("test-1", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return clientCtx
+}
+
+func newTestSigner() Signer {
+	return NewPrivKeySigner(secp256k1.GenPrivKey())
+}
+
+// newTestChainClient returns a client signing with signer, connected to a pool of the nodes.
+func newTestChainClient(t *testing.T, signer Signer, nodes []*fakeNode, options ...common.ClientOption) *chainClient {
+	t.Helper()
+
+	return newTestChainClientWithContext(t, newTestClientContext(t), signer, nodes, options...)
+}
+
+// newTestChainClientWithContext works like newTestChainClient, signing with the from key of
+// the keyring in clientCtx when signer is nil.
+func newTestChainClientWithContext(t *testing.T, clientCtx client.Context, signer Signer, nodes []*fakeNode, options ...common.ClientOption) *chainClient {
+	t.Helper()
+
+	poolNodes := make([]PoolNode, 0, len(nodes))
+	for _, node := range nodes {
+		poolNodes = append(poolNodes, PoolNode{Address: node.addr})
+	}
+	pool, err := NewEndpointPool(poolNodes, OptionEndpointRetryPolicy(noRetryPolicy()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options = append([]common.ClientOption{
+		common.OptionBroadcastStatusPoll(10 * time.Millisecond),
+		common.OptionTimeoutHeightSyncInterval(10 * time.Millisecond),
+	}, options...)
+
+	c, err := NewChainClientWithEndpointPool(clientCtx, signer, pool, options...)
+	if err != nil {
+		pool.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+
+	return c.(*chainClient)
+}
+
+// awaitCachedHeight waits until the block height cached by c for Tx timeout heights reaches height.
+func awaitCachedHeight(t *testing.T, c *chainClient, height int64) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt64(&c.lastHeight) < height {
+		if time.Now().After(deadline) {
+			t.Fatalf("cached block height %d did not reach %d", atomic.LoadInt64(&c.lastHeight), height)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,203 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/InjectiveLabs/suplog"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+const nodeConfigMethod = "/cosmos.base.node.v1beta1.Service/Config"
+
+var ErrFeeAboveMax = errors.New("estimated fee exceeds the max fee")
+
+// feeEstimator computes Tx fees from the gas limit using the configured gas prices and
+// the minimum gas prices of the node, whichever is higher for each denom. The node config
+// service is only queried when enabled with common.OptionGasPricesRefreshInterval, otherwise
+// the node minimum gas prices are learned from Txs rejected for insufficient fees.
+type feeEstimator struct {
+	conn   grpc.ClientConnInterface
+	opts   *common.ClientOptions
+	logger log.Logger
+
+	configured sdk.DecCoins
+
+	mux          sync.RWMutex
+	nodePrices   sdk.DecCoins
+	nodeSyncedAt time.Time
+	// nodeConfigUnsupported is set once the node has no config service to query
+	nodeConfigUnsupported bool
+}
+
+func newFeeEstimator(conn grpc.ClientConnInterface, opts *common.ClientOptions) (*feeEstimator, error) {
+	configured, err := sdk.ParseDecCoins(opts.GasPrices)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to ParseDecCoins %s", opts.GasPrices)
+		return nil, err
+	}
+
+	return &feeEstimator{
+		conn: conn,
+		opts: opts,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "feeEstimator",
+		}),
+		configured: configured,
+	}, nil
+}
+
+// GasPrices returns the effective gas prices, refreshing the node minimum gas prices when stale.
+func (e *feeEstimator) GasPrices(ctx context.Context) sdk.DecCoins {
+	e.mux.RLock()
+	stale := e.opts.GasPricesRefreshInterval > 0 && time.Since(e.nodeSyncedAt) > e.opts.GasPricesRefreshInterval &&
+		!e.nodeConfigUnsupported
+	e.mux.RUnlock()
+
+	if stale {
+		e.syncNodeGasPrices(ctx)
+	}
+
+	e.mux.RLock()
+	defer e.mux.RUnlock()
+
+	return maxDecCoins(e.configured, e.nodePrices)
+}
+
+// EstimateFee computes the fee for the gas limit in the first fee denom that has a gas price
+// and fits into the max fee. An empty fee is returned when no gas prices are known.
+func (e *feeEstimator) EstimateFee(ctx context.Context, gas uint64) (sdk.Coins, error) {
+	gasPrices := e.GasPrices(ctx)
+	if gasPrices.IsZero() {
+		return sdk.Coins{}, nil
+	}
+
+	denoms := e.opts.FeeDenoms
+	if len(denoms) == 0 {
+		// the chain fee denom is preferred over the other denoms with gas prices
+		denoms = append(denoms, e.opts.ChainConfig.FeeDenom)
+		for _, price := range gasPrices {
+			if price.Denom != e.opts.ChainConfig.FeeDenom {
+				denoms = append(denoms, price.Denom)
+			}
+		}
+	}
+
+	gasLimit := sdk.NewDec(int64(gas))
+	var lastFee sdk.Coin
+	for _, denom := range denoms {
+		price := gasPrices.AmountOf(denom)
+		if price.IsZero() {
+			continue
+		}
+
+		lastFee = sdk.NewCoin(denom, price.Mul(gasLimit).Ceil().RoundInt())
+		if maxAmount := e.opts.MaxFee.AmountOf(denom); !maxAmount.IsZero() && lastFee.Amount.GT(maxAmount) {
+			continue
+		}
+
+		return sdk.NewCoins(lastFee), nil
+	}
+
+	if lastFee.Denom == "" {
+		err := errors.Errorf("no gas price for fee denoms %v", denoms)
+		return nil, err
+	}
+
+	return nil, errors.Wrapf(ErrFeeAboveMax, "%s > %s", lastFee.String(), e.opts.MaxFee.String())
+}
+
+// observeInsufficientFee learns the node minimum gas price from a rejected Tx.
+func (e *feeEstimator) observeInsufficientFee(feeErr *ErrInsufficientFee, gas uint64) {
+	if gas == 0 {
+		return
+	}
+
+	required, err := sdk.ParseDecCoins(feeErr.Required)
+	if err != nil || required.IsZero() {
+		return
+	}
+
+	prices := required.QuoDec(sdk.NewDec(int64(gas)))
+
+	e.mux.Lock()
+	e.nodePrices = maxDecCoins(e.nodePrices, prices)
+	e.mux.Unlock()
+
+	e.logger.Debugln("learned node min gas prices from rejected tx:", prices.String())
+}
+
+func (e *feeEstimator) syncNodeGasPrices(ctx context.Context) {
+	e.mux.Lock()
+	e.nodeSyncedAt = time.Now()
+	e.mux.Unlock()
+
+	res := &nodeConfigResponse{}
+	if err := e.conn.Invoke(ctx, nodeConfigMethod, &nodeConfigRequest{}, res); err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			// the config service is only served from cosmos-sdk v0.46 on, older nodes
+			// reveal their min gas prices by rejecting Txs only
+			e.mux.Lock()
+			e.nodeConfigUnsupported = true
+			e.mux.Unlock()
+			return
+		}
+
+		e.logger.WithError(err).Debugln("failed to query node min gas prices")
+		return
+	}
+
+	prices, err := sdk.ParseDecCoins(res.MinimumGasPrice)
+	if err != nil {
+		e.logger.WithError(err).Warningln("failed to parse node min gas prices", res.MinimumGasPrice)
+		return
+	}
+
+	e.mux.Lock()
+	e.nodePrices = prices
+	e.mux.Unlock()
+}
+
+// maxDecCoins returns the highest amount of each denom from both coin sets.
+func maxDecCoins(a, b sdk.DecCoins) sdk.DecCoins {
+	res := sdk.NewDecCoins()
+	for _, coin := range a {
+		if coin.Amount.GTE(b.AmountOf(coin.Denom)) {
+			res = res.Add(coin)
+		}
+	}
+	for _, coin := range b {
+		if coin.Amount.GT(a.AmountOf(coin.Denom)) {
+			res = res.Add(coin)
+		}
+	}
+
+	return res
+}
+
+// nodeConfigRequest and nodeConfigResponse mirror the cosmos.base.node.v1beta1 Config messages,
+// which are not part of the cosmos-sdk version this module depends on.
+type nodeConfigRequest struct{}
+
+func (m *nodeConfigRequest) Reset()         { *m = nodeConfigRequest{} }
+func (m *nodeConfigRequest) String() string { return "ConfigRequest{}" }
+func (*nodeConfigRequest) ProtoMessage()    {}
+
+type nodeConfigResponse struct {
+	MinimumGasPrice string `protobuf:"bytes,1,opt,name=minimum_gas_price,json=minimumGasPrice,proto3" json:"minimum_gas_price,omitempty"`
+}
+
+func (m *nodeConfigResponse) Reset() { *m = nodeConfigResponse{} }
+func (m *nodeConfigResponse) String() string {
+	return fmt.Sprintf("ConfigResponse{MinimumGasPrice: %s}", m.MinimumGasPrice)
+}
+func (*nodeConfigResponse) ProtoMessage() {}
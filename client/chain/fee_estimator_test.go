@@ -0,0 +1,230 @@
+package chain
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// fakeConfigConn serves the node config query with the min gas prices, or fails it with err.
+type fakeConfigConn struct {
+	minGasPrices string
+	err          error
+	calls        int64
+}
+
+func (c *fakeConfigConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	atomic.AddInt64(&c.calls, 1)
+	if method != nodeConfigMethod {
+		return status.Error(codes.Unimplemented, method)
+	} else if c.err != nil {
+		return c.err
+	}
+
+	reply.(*nodeConfigResponse).MinimumGasPrice = c.minGasPrices
+	return nil
+}
+
+func (c *fakeConfigConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, status.Error(codes.Unimplemented, method)
+}
+
+func newTestFeeEstimator(t *testing.T, conn grpc.ClientConnInterface, options ...common.ClientOption) *feeEstimator {
+	t.Helper()
+
+	opts := common.DefaultClientOptions()
+	for _, option := range options {
+		if err := option(opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	e, err := newFeeEstimator(conn, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return e
+}
+
+func TestFeeEstimatorUsesHigherNodeGasPrices(t *testing.T) {
+	conn := &fakeConfigConn{minGasPrices: "0.5aaa,2bbb"}
+	e := newTestFeeEstimator(t, conn,
+		common.OptionGasPrices("1aaa,1bbb"),
+		common.OptionGasPricesRefreshInterval(time.Hour),
+	)
+
+	if prices := e.GasPrices(context.Background()); prices.String() != "1.000000000000000000aaa,2.000000000000000000bbb" {
+		t.Fatalf("unexpected gas prices %s", prices)
+	}
+}
+
+func TestFeeEstimatorDoesNotQueryNodeByDefault(t *testing.T) {
+	conn := &fakeConfigConn{minGasPrices: "2aaa"}
+	e := newTestFeeEstimator(t, conn, common.OptionGasPrices("1aaa"))
+
+	if prices := e.GasPrices(context.Background()); prices.String() != "1.000000000000000000aaa" {
+		t.Fatalf("expected the configured gas prices, got %s", prices)
+	} else if calls := atomic.LoadInt64(&conn.calls); calls != 0 {
+		t.Fatalf("expected the node config not to be queried, got %d queries", calls)
+	}
+}
+
+// startNodeConfigServer serves the node config service of cosmos-sdk v0.46 nodes over bufconn,
+// or no service at all when minGasPrices is empty, like older nodes.
+func startNodeConfigServer(t *testing.T, minGasPrices string) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	if minGasPrices != "" {
+		server.RegisterService(&grpc.ServiceDesc{
+			ServiceName: "cosmos.base.node.v1beta1.Service",
+			HandlerType: (*interface{})(nil),
+			Methods: []grpc.MethodDesc{{
+				MethodName: "Config",
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+					if err := dec(&nodeConfigRequest{}); err != nil {
+						return nil, err
+					}
+					return &nodeConfigResponse{MinimumGasPrice: minGasPrices}, nil
+				},
+			}},
+		}, struct{}{})
+	}
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+func TestFeeEstimatorQueriesNodeConfigService(t *testing.T) {
+	conn := startNodeConfigServer(t, "0.5aaa,2bbb")
+	e := newTestFeeEstimator(t, conn,
+		common.OptionGasPrices("1aaa"),
+		common.OptionGasPricesRefreshInterval(time.Hour),
+	)
+
+	if prices := e.GasPrices(context.Background()); prices.String() != "1.000000000000000000aaa,2.000000000000000000bbb" {
+		t.Fatalf("unexpected gas prices %s", prices)
+	}
+}
+
+func TestFeeEstimatorWithoutNodeConfigService(t *testing.T) {
+	conn := startNodeConfigServer(t, "")
+	e := newTestFeeEstimator(t, conn,
+		common.OptionGasPrices("1aaa"),
+		common.OptionGasPricesRefreshInterval(time.Hour),
+	)
+
+	if prices := e.GasPrices(context.Background()); prices.String() != "1.000000000000000000aaa" {
+		t.Fatalf("expected the configured gas prices, got %s", prices)
+	} else if !e.nodeConfigUnsupported {
+		t.Fatal("expected the missing node config service to be detected")
+	}
+}
+
+func TestFeeEstimatorStopsQueryingUnimplementedNodeConfig(t *testing.T) {
+	conn := &fakeConfigConn{err: status.Error(codes.Unimplemented, "unknown service cosmos.base.node.v1beta1.Service")}
+	e := newTestFeeEstimator(t, conn,
+		common.OptionGasPrices("1aaa"),
+		common.OptionGasPricesRefreshInterval(time.Nanosecond),
+	)
+
+	for i := 0; i < 3; i++ {
+		if prices := e.GasPrices(context.Background()); !prices.IsEqual(sdk.NewDecCoins(sdk.NewInt64DecCoin("aaa", 1))) {
+			t.Fatalf("expected the configured gas prices, got %s", prices)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if calls := atomic.LoadInt64(&conn.calls); calls != 1 {
+		t.Fatalf("expected the node config to be queried once, got %d queries", calls)
+	}
+}
+
+func TestFeeEstimatorLearnsFromInsufficientFee(t *testing.T) {
+	conn := &fakeConfigConn{err: status.Error(codes.Unimplemented, "")}
+	e := newTestFeeEstimator(t, conn, common.OptionGasPrices("1aaa"))
+
+	e.observeInsufficientFee(&ErrInsufficientFee{Got: "100aaa", Required: "300aaa"}, 100)
+
+	fee, err := e.EstimateFee(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	} else if fee.String() != "30aaa" {
+		t.Fatalf("expected the learned gas price to be used, got %s", fee)
+	}
+}
+
+func TestFeeEstimatorFallsBackBelowMaxFee(t *testing.T) {
+	conn := &fakeConfigConn{err: status.Error(codes.Unimplemented, "")}
+	e := newTestFeeEstimator(t, conn,
+		common.OptionGasPrices("10aaa,1bbb"),
+		common.OptionFeeDenoms("aaa", "bbb"),
+		common.OptionMaxFee("50aaa,50bbb"),
+	)
+
+	if fee, err := e.EstimateFee(context.Background(), 10); err != nil {
+		t.Fatal(err)
+	} else if fee.String() != "10bbb" {
+		t.Fatalf("expected the fee in the next denom below the max fee, got %s", fee)
+	}
+
+	if _, err := e.EstimateFee(context.Background(), 100); errors.Cause(err) != ErrFeeAboveMax {
+		t.Fatalf("expected ErrFeeAboveMax, got %v", err)
+	}
+}
+
+func TestChainClientGasFeeOfBroadcastTxsOnly(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	if _, err := c.GetGasFee(); err == nil {
+		t.Fatal("expected no fee before the first tx")
+	}
+
+	overrides := TxOverrides{Gas: fakeGasUsed, Fees: "5aaa"}
+	if _, err := c.BroadcastMsgsWithOverrides(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, overrides, newTestMsgSend(signer)); err != nil {
+		t.Fatal(err)
+	}
+
+	// rejected by CheckTx, its fee is not charged
+	overrides = TxOverrides{Gas: fakeGasUsed, Fees: "7aaa", TimeoutHeight: 1}
+	if _, err := c.BroadcastMsgsWithOverrides(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_SYNC, overrides, newTestMsgSend(signer)); err == nil {
+		t.Fatal("expected the expired tx to be rejected")
+	}
+
+	if fee, err := c.GetGasFee(); err != nil {
+		t.Fatal(err)
+	} else if fee.String() != "5aaa" {
+		t.Fatalf("expected the fee of the broadcast tx, got %s", fee)
+	}
+}
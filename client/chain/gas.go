@@ -0,0 +1,137 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/pkg/errors"
+)
+
+// fromPubKey returns the pubkey of the signer, or of the watch-only from key in the keyring.
+func (c *chainClient) fromPubKey() cryptotypes.PubKey {
+	if c.signer != nil {
+		return c.signer.PubKey()
+	} else if c.ctx.Keyring == nil {
+		return nil
+	}
+
+	info, err := c.ctx.Keyring.Key(c.ctx.GetFromName())
+	if err != nil {
+		return nil
+	}
+
+	return info.GetPubKey()
+}
+
+// resolveMaxGasPerTx returns the configured max gas per Tx, or falls back to
+// the block max gas from the chain consensus params. Zero means unlimited.
+func (c *chainClient) resolveMaxGasPerTx() uint64 {
+	if c.opts.MaxGasPerTx > 0 {
+		return c.opts.MaxGasPerTx
+	} else if c.ctx.Client == nil {
+		return 0
+	}
+
+	params, err := c.ctx.Client.ConsensusParams(context.Background(), nil)
+	if err != nil {
+		c.logger.WithError(err).Warningln("failed to get consensus params, batches won't be limited by gas")
+		return 0
+	}
+
+	// -1 stands for unlimited block gas
+	if maxGas := params.ConsensusParams.Block.MaxGas; maxGas > 0 {
+		return uint64(maxGas)
+	}
+
+	return 0
+}
+
+// estimateGas simulates msgs as a single Tx and returns the adjusted gas it would need.
+// Must be called with c.syncMux held.
+func (c *chainClient) estimateGas(msgs []sdk.Msg) (uint64, error) {
+	txf := c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+	return c.simulateGas(context.Background(), txf, c.signer.PubKey(), msgs)
+}
+
+// simulateGas simulates msgs as a single Tx signed by pubKey and returns
+// the gas used, adjusted by the gas adjustment of txf.
+func (c *chainClient) simulateGas(ctx context.Context, txf tx.Factory, pubKey cryptotypes.PubKey, msgs []sdk.Msg) (uint64, error) {
+	simTxBytes, err := buildSimTx(c.ctx.TxConfig, txf, pubKey, msgs)
+	if err != nil {
+		err = errors.Wrap(err, "failed to build sim tx bytes")
+		return 0, err
+	}
+
+	simRes, err := c.txClient.Simulate(ctx, &txtypes.SimulateRequest{TxBytes: simTxBytes})
+	if err != nil {
+		err = errors.Wrap(ParseGRPCError(err), "failed to CalculateGas")
+		return 0, err
+	}
+
+	return uint64(txf.GasAdjustment() * float64(simRes.GasInfo.GasUsed)), nil
+}
+
+// buildSimTx builds a Tx with an empty signature of pubKey, so simulation charges
+// the verification gas of the actual key type. Without pubKey, a secp256k1 one is assumed.
+func buildSimTx(txConfig client.TxConfig, txf tx.Factory, pubKey cryptotypes.PubKey, msgs []sdk.Msg) ([]byte, error) {
+	if pubKey == nil {
+		pubKey = &secp256k1.PubKey{}
+	}
+
+	txn, err := tx.BuildUnsignedTx(txf, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := signingtypes.SignatureV2{
+		PubKey: pubKey,
+		Data: &signingtypes.SingleSignatureData{
+			SignMode: txf.SignMode(),
+		},
+		Sequence: txf.Sequence(),
+	}
+	if err := txn.SetSignatures(sig); err != nil {
+		return nil, err
+	}
+
+	return txConfig.TxEncoder()(txn.GetTx())
+}
+
+// splitBatchByGas splits the batch into chunks that fit into the max gas per Tx.
+// Chunks are found by bisecting the batch until the simulated gas of each one fits.
+// Must be called with c.syncMux held.
+func (c *chainClient) splitBatchByGas(batch []queuedMsg) [][]queuedMsg {
+	if c.maxGasPerTx == 0 || len(batch) <= 1 {
+		return [][]queuedMsg{batch}
+	}
+
+	msgs := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		msgs = append(msgs, queued.msg)
+	}
+
+	gas, err := c.estimateGas(msgs)
+	if err != nil {
+		// let the broadcast report the actual error
+		c.logger.WithError(err).Debugln("failed to estimate msg batch gas")
+		return [][]queuedMsg{batch}
+	} else if gas <= c.maxGasPerTx {
+		return [][]queuedMsg{batch}
+	}
+
+	c.logger.WithField("size", len(batch)).Debugln("msg batch exceeds max gas per tx, splitting:", gas, ">", c.maxGasPerTx)
+
+	half := len(batch) / 2
+	return append(c.splitBatchByGas(batch[:half]), c.splitBatchByGas(batch[half:])...)
+}
+
+func isOutOfGas(res *sdk.TxResponse) bool {
+	var gasErr *ErrOutOfGas
+	return errors.As(ParseTxError(res), &gasErr)
+}
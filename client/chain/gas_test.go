@@ -0,0 +1,132 @@
+package chain
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func newTestMsgBatch(signer Signer, n int) []queuedMsg {
+	batch := make([]queuedMsg, 0, n)
+	for i := 0; i < n; i++ {
+		batch = append(batch, queuedMsg{msg: newTestMsgSend(signer)})
+	}
+
+	return batch
+}
+
+func TestSplitBatchByGas(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	// with the default 1.5 gas adjustment, two msgs fit and three don't
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionMaxGasPerTx(4*fakeGasUsed))
+
+	c.syncMux.Lock()
+	chunks := c.splitBatchByGas(newTestMsgBatch(signer, 5))
+	c.syncMux.Unlock()
+
+	sizes := make([]int, 0, len(chunks))
+	for _, chunk := range chunks {
+		sizes = append(sizes, len(chunk))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 1 || sizes[2] != 2 {
+		t.Fatalf("expected the batch to be split into chunks of 2, 1 and 2 msgs, got %v", sizes)
+	}
+
+	unlimited := newTestChainClient(t, signer, []*fakeNode{a})
+	unlimited.syncMux.Lock()
+	chunks = unlimited.splitBatchByGas(newTestMsgBatch(signer, 5))
+	unlimited.syncMux.Unlock()
+
+	if len(chunks) != 1 || len(chunks[0]) != 5 {
+		t.Fatalf("expected the batch not to be split without max gas, got %d chunks", len(chunks))
+	}
+}
+
+// outOfGasAbove makes included Txs with more than n msgs use twice the simulated gas.
+func outOfGasAbove(n int) func(msgs int) uint64 {
+	return func(msgs int) uint64 {
+		if msgs > n {
+			return 2 * uint64(msgs) * fakeGasUsed
+		}
+
+		return uint64(msgs) * fakeGasUsed
+	}
+}
+
+func TestQueuedBatchBisectsOnOutOfGas(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	chain.deliverGas = outOfGasAbove(2)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionBatchSizeLimit(4))
+
+	msgs := make([]sdk.Msg, 0, 4)
+	for i := 0; i < 4; i++ {
+		msgs = append(msgs, newTestMsgSend(signer))
+	}
+
+	futures, err := c.QueueBroadcastMsgWithResult(msgs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx, result := range awaitFutures(t, futures) {
+		if result.Err != nil {
+			t.Fatalf("expected msg %d to be committed, got %v", idx, result.Err)
+		}
+	}
+
+	broadcasts := chain.sentBroadcasts()
+	if len(broadcasts) != 3 || broadcasts[0].msgs != 4 || broadcasts[1].msgs != 2 || broadcasts[2].msgs != 2 {
+		t.Fatalf("expected the batch to be retried in halves, got %d broadcasts", len(broadcasts))
+	} else if seq := chain.account(signer.Address()).seq; seq != 3 {
+		t.Fatalf("expected the failed tx to consume a sequence, got %d", seq)
+	}
+}
+
+func TestPipelinedBatchBisectsOnOutOfGas(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	chain.deliverGas = outOfGasAbove(1)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	futures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer), newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for idx, result := range awaitFutures(t, futures) {
+		if result.Err != nil {
+			t.Fatalf("expected msg %d to be committed, got %v", idx, result.Err)
+		}
+	}
+
+	if broadcasts := chain.sentBroadcasts(); len(broadcasts) != 3 {
+		t.Fatalf("expected the batch to be retried msg by msg, got %d broadcasts", len(broadcasts))
+	}
+}
+
+func TestIsOutOfGas(t *testing.T) {
+	res := &sdk.TxResponse{Codespace: "sdk", Code: 11, GasWanted: 10, GasUsed: 20}
+	if !isOutOfGas(res) {
+		t.Fatal("expected the response to be out of gas")
+	}
+
+	var gasErr *ErrOutOfGas
+	if err := ParseTxError(res); !errors.As(err, &gasErr) || gasErr.Wanted != 10 || gasErr.Used != 20 {
+		t.Fatalf("unexpected error %v", err)
+	} else if isOutOfGas(&sdk.TxResponse{}) {
+		t.Fatal("expected a successful response not to be out of gas")
+	}
+}
@@ -3,11 +3,13 @@ package chain
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/hex"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
 
+	"github.com/cosmos/cosmos-sdk/client"
 	cosmcrypto "github.com/cosmos/cosmos-sdk/crypto"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
@@ -32,40 +34,110 @@ func InitCosmosKeyring(
 	cosmosPrivKey string,
 	cosmosUseLedger bool,
 ) (cosmtypes.AccAddress, keyring.Keyring, error) {
+	return InitCosmosKeyringWithAlgo(
+		cosmosKeyringDir,
+		cosmosKeyringAppName,
+		cosmosKeyringBackend,
+		cosmosKeyFrom,
+		cosmosKeyPassphrase,
+		cosmosPrivKey,
+		cosmosUseLedger,
+		hd.EthSecp256k1,
+	)
+}
+
+// InitCosmosKeyringWithAlgo works like InitCosmosKeyring, with keys of the signing algorithm:
+// hd.EthSecp256k1 for Ethermint chains, or the cosmos hd.Secp256k1 for vanilla Cosmos chains.
+// Keyring entries must have been created with the same algorithm.
+func InitCosmosKeyringWithAlgo(
+	cosmosKeyringDir string,
+	cosmosKeyringAppName string,
+	cosmosKeyringBackend string,
+	cosmosKeyFrom string,
+	cosmosKeyPassphrase string,
+	cosmosPrivKey string,
+	cosmosUseLedger bool,
+	algo keyring.SignatureAlgo,
+) (cosmtypes.AccAddress, keyring.Keyring, error) {
+	cfg := KeyringConfig{
+		Dir:       cosmosKeyringDir,
+		AppName:   cosmosKeyringAppName,
+		Backend:   cosmosKeyringBackend,
+		KeyFrom:   cosmosKeyFrom,
+		UseLedger: cosmosUseLedger,
+		Algo:      algo,
+	}
+	if len(cosmosKeyPassphrase) > 0 {
+		cfg.Passphrase = PlainSecret(cosmosKeyPassphrase)
+	}
+	if len(cosmosPrivKey) > 0 {
+		cfg.PrivKey = PlainSecret(cosmosPrivKey)
+	}
+
+	return InitCosmosKeyringWithConfig(cfg)
+}
+
+// KeyringConfig configures the keyring and the key used by a chain client. Secrets are read
+// from their sources only when needed, and their buffers are zeroed after use on a best effort
+// basis, see SecretSource.
+type KeyringConfig struct {
+	// Dir, AppName and Backend locate the cosmos keyring, e.g. the "file" backend in Dir
+	Dir     string
+	AppName string
+	Backend string
+	// KeyFrom is the name or the bech32 address of the key
+	KeyFrom string
+	// Passphrase unlocks the keyring. When nil, the keyring prompts on os.Stdin.
+	Passphrase SecretSource
+	// PrivKey provides a hex private key used instead of the keyring, wrapped into an in-mem keyring
+	PrivKey   SecretSource
+	UseLedger bool
+	// Algo is the signing algorithm of the key, hd.EthSecp256k1 by default
+	Algo keyring.SignatureAlgo
+	// ChainConfig has the bech32 prefix KeyFrom addresses are parsed with, common.DefaultChainConfig by default
+	ChainConfig *common.ChainConfig
+}
+
+// InitCosmosKeyringWithConfig works like InitCosmosKeyring, reading the keyring passphrase
+// and the private key from the secret sources of the config, e.g. EnvSecret or FileSecret.
+func InitCosmosKeyringWithConfig(cfg KeyringConfig) (cosmtypes.AccAddress, keyring.Keyring, error) {
+	algo := cfg.Algo
+	if algo == nil {
+		algo = hd.EthSecp256k1
+	}
+
+	chainConfig := common.DefaultChainConfig()
+	if cfg.ChainConfig != nil {
+		chainConfig = *cfg.ChainConfig
+	}
+
 	switch {
-	case len(cosmosPrivKey) > 0:
-		if cosmosUseLedger {
+	case cfg.PrivKey != nil:
+		if cfg.UseLedger {
 			err := errors.New("cannot combine ledger and privkey options")
 			return emptyCosmosAddress, nil, err
 		}
 
-		pkBytes, err := common.HexToBytes(cosmosPrivKey)
+		cosmosAccPk, err := privKeyFromSecret(cfg.PrivKey, algo)
 		if err != nil {
-			err = errors.Wrap(err, "failed to hex-decode cosmos account privkey")
 			return emptyCosmosAddress, nil, err
 		}
 
-		// Specfic to Injective chain with Ethermint keys
-		// Should be secp256k1.PrivKey for generic Cosmos chain
-		cosmosAccPk := &ethsecp256k1.PrivKey{
-			Key: pkBytes,
-		}
-
 		addressFromPk := cosmtypes.AccAddress(cosmosAccPk.PubKey().Address().Bytes())
 
 		var keyName string
 
 		// check that if cosmos 'From' specified separately, it must match the provided privkey,
-		if len(cosmosKeyFrom) > 0 {
-			addressFrom, err := cosmtypes.AccAddressFromBech32(cosmosKeyFrom)
+		if len(cfg.KeyFrom) > 0 {
+			addressFrom, err := chainConfig.ParseAccAddress(cfg.KeyFrom)
 			if err == nil {
 				if !bytes.Equal(addressFrom.Bytes(), addressFromPk.Bytes()) {
-					err = errors.Errorf("expected account address %s but got %s from the private key", addressFrom.String(), addressFromPk.String())
+					err = errors.Errorf("expected account address %s but got %s from the private key", cfg.KeyFrom, chainConfig.FormatAccAddress(addressFromPk))
 					return emptyCosmosAddress, nil, err
 				}
 			} else {
 				// use it as a name then
-				keyName = cosmosKeyFrom
+				keyName = cfg.KeyFrom
 			}
 		}
 
@@ -77,28 +149,28 @@ func InitCosmosKeyring(
 		kb, err := KeyringForPrivKey(keyName, cosmosAccPk)
 		return addressFromPk, kb, err
 
-	case len(cosmosKeyFrom) > 0:
+	case len(cfg.KeyFrom) > 0:
 		var fromIsAddress bool
-		addressFrom, err := cosmtypes.AccAddressFromBech32(cosmosKeyFrom)
+		addressFrom, err := chainConfig.ParseAccAddress(cfg.KeyFrom)
 		if err == nil {
 			fromIsAddress = true
 		}
 
 		var passReader io.Reader = os.Stdin
-		if len(cosmosKeyPassphrase) > 0 {
-			passReader = newPassReader(cosmosKeyPassphrase)
+		if cfg.Passphrase != nil {
+			passReader = newSecretReader(cfg.Passphrase)
 		}
 
 		var absoluteKeyringDir string
-		if filepath.IsAbs(cosmosKeyringDir) {
-			absoluteKeyringDir = cosmosKeyringDir
+		if filepath.IsAbs(cfg.Dir) {
+			absoluteKeyringDir = cfg.Dir
 		} else {
-			absoluteKeyringDir, _ = filepath.Abs(cosmosKeyringDir)
+			absoluteKeyringDir, _ = filepath.Abs(cfg.Dir)
 		}
 
 		kb, err := keyring.New(
-			cosmosKeyringAppName,
-			cosmosKeyringBackend,
+			cfg.AppName,
+			cfg.Backend,
 			absoluteKeyringDir,
 			passReader,
 			hd.EthSecp256k1Option(),
@@ -111,12 +183,19 @@ func InitCosmosKeyring(
 		var keyInfo keyring.Info
 		if fromIsAddress {
 			if keyInfo, err = kb.KeyByAddress(addressFrom); err != nil {
-				err = errors.Wrapf(err, "couldn't find an entry for the key %s in keybase", addressFrom.String())
+				err = errors.Wrapf(err, "couldn't find an entry for the key %s in keybase", cfg.KeyFrom)
 				return emptyCosmosAddress, nil, err
 			}
 		} else {
-			if keyInfo, err = kb.Key(cosmosKeyFrom); err != nil {
-				err = errors.Wrapf(err, "could not find an entry for the key '%s' in keybase", cosmosKeyFrom)
+			if keyInfo, err = kb.Key(cfg.KeyFrom); err != nil {
+				err = errors.Wrapf(err, "could not find an entry for the key '%s' in keybase", cfg.KeyFrom)
+				return emptyCosmosAddress, nil, err
+			}
+		}
+
+		if keyType := keyInfo.GetType(); keyType == keyring.TypeLocal || keyType == keyring.TypeLedger {
+			if keyInfo.GetAlgo() != algo.Name() {
+				err := errors.Errorf("'%s' key uses %s algorithm, expected %s", keyInfo.GetName(), keyInfo.GetAlgo(), algo.Name())
 				return emptyCosmosAddress, nil, err
 			}
 		}
@@ -128,17 +207,17 @@ func InitCosmosKeyring(
 		case keyring.TypeLedger:
 			// the kb stores references to ledger keys, so we must explicitly
 			// check that. kb doesn't know how to scan HD keys - they must be added manually before
-			if cosmosUseLedger {
+			if cfg.UseLedger {
 				return keyInfo.GetAddress(), kb, nil
 			}
 			err := errors.Errorf("'%s' key is a ledger reference, enable ledger option", keyInfo.GetName())
 			return emptyCosmosAddress, nil, err
 		case keyring.TypeOffline:
-			err := errors.Errorf("'%s' key is an offline key, not supported yet", keyInfo.GetName())
-			return emptyCosmosAddress, nil, err
+			// offline keys are watch-only, Txs are signed by an external signer
+			return keyInfo.GetAddress(), kb, nil
 		case keyring.TypeMulti:
-			err := errors.Errorf("'%s' key is an multisig key, not supported yet", keyInfo.GetName())
-			return emptyCosmosAddress, nil, err
+			// multisig keys can't sign alone, Txs are signed by members and combined offline
+			return keyInfo.GetAddress(), kb, nil
 		default:
 			err := errors.Errorf("'%s' key  has unsupported type: %s", keyInfo.GetName(), keyType)
 			return emptyCosmosAddress, nil, err
@@ -150,29 +229,48 @@ func InitCosmosKeyring(
 	}
 }
 
-func newPassReader(pass string) io.Reader {
-	return &passReader{
-		pass: pass,
-		buf:  new(bytes.Buffer),
+// canSignWith reports whether the from key of the context can sign Txs on its own.
+// Offline and multisig keys are watch-only references to pubkeys and have no private key.
+func canSignWith(clientCtx client.Context) bool {
+	if clientCtx.Keyring == nil {
+		return false
+	}
+
+	info, err := clientCtx.Keyring.Key(clientCtx.GetFromName())
+	if err != nil {
+		return false
 	}
-}
 
-type passReader struct {
-	pass string
-	buf  *bytes.Buffer
+	switch info.GetType() {
+	case keyring.TypeOffline, keyring.TypeMulti:
+		return false
+	default:
+		return true
+	}
 }
 
-var _ io.Reader = &passReader{}
+// privKeyFromSecret decodes the hex private key read from the source, zeroing the secret bytes.
+func privKeyFromSecret(source SecretSource, algo keyring.SignatureAlgo) (cryptotypes.PrivKey, error) {
+	secret, err := readSecret(source, "cosmos account privkey")
+	if err != nil {
+		return nil, err
+	}
+	defer zeroBytes(secret)
 
-func (r *passReader) Read(p []byte) (n int, err error) {
-	n, err = r.buf.Read(p)
-	if err == io.EOF || n == 0 {
-		r.buf.WriteString(r.pass + "\n")
+	privKeyHex := bytes.TrimPrefix(secret, []byte("0x"))
+	pkBytes := make([]byte, hex.DecodedLen(len(privKeyHex)))
+	defer zeroBytes(pkBytes)
 
-		n, err = r.buf.Read(p)
+	if _, err := hex.Decode(pkBytes, privKeyHex); err != nil {
+		// the decoding error would include the invalid byte of the secret
+		return nil, errors.New("failed to hex-decode cosmos account privkey")
+	} else if len(pkBytes) != ethsecp256k1.PrivKeySize {
+		err = errors.Errorf("invalid privkey length %d, expected %d", len(pkBytes), ethsecp256k1.PrivKeySize)
+		return nil, err
 	}
 
-	return
+	// the algorithm copies the key bytes
+	return algo.Generate()(pkBytes), nil
 }
 
 // KeyringForPrivKey creates a temporary in-mem keyring for a PrivKey.
@@ -190,6 +288,25 @@ func KeyringForPrivKey(name string, privKey cryptotypes.PrivKey) (keyring.Keyrin
 	return kb, nil
 }
 
+// KeyringForMnemonic creates a temporary in-mem keyring with the key derived from the mnemonic
+// at the HD path, using the signing algorithm, e.g. hd.EthSecp256k1. The keyring and the key name
+// can be passed to NewClientContext.
+func KeyringForMnemonic(
+	name string,
+	mnemonic string,
+	bip39Passphrase string,
+	hdPath string,
+	algo keyring.SignatureAlgo,
+) (keyring.Keyring, error) {
+	kb := keyring.NewInMemory(hd.EthSecp256k1Option())
+	if _, err := kb.NewAccount(name, mnemonic, bip39Passphrase, hdPath, algo); err != nil {
+		err = errors.Wrapf(err, "failed to derive key at %s", hdPath)
+		return nil, err
+	}
+
+	return kb, nil
+}
+
 func randPhrase(size int) string {
 	buf := make([]byte, size)
 	_, err := rand.Read(buf)
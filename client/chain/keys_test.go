@@ -0,0 +1,71 @@
+package chain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	cosmoshd "github.com/cosmos/cosmos-sdk/crypto/hd"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+	"github.com/gotabit/sdk-go/chain/crypto/hd"
+)
+
+func TestPrivKeyFromSecret(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privKeyHex := hex.EncodeToString(privKey.Bytes())
+
+	for _, secret := range []string{privKeyHex, "0x" + privKeyHex} {
+		parsed, err := privKeyFromSecret(PlainSecret(secret), hd.EthSecp256k1)
+		if err != nil {
+			t.Fatal(err)
+		} else if !parsed.Equals(privKey) {
+			t.Fatalf("expected the key to be parsed from %q", secret)
+		}
+	}
+
+	cosmosKey, err := privKeyFromSecret(PlainSecret(privKeyHex), cosmoshd.Secp256k1)
+	if err != nil {
+		t.Fatal(err)
+	} else if cosmosKey.PubKey().Address().String() == privKey.PubKey().Address().String() {
+		t.Fatal("expected the secp256k1 key to have a RIPEMD160 address")
+	}
+}
+
+func TestPrivKeyFromSecretRejectsInvalidKeys(t *testing.T) {
+	for _, secret := range []string{"", "zz", "0xabcd"} {
+		if _, err := privKeyFromSecret(PlainSecret(secret), hd.EthSecp256k1); err == nil {
+			t.Fatalf("expected %q to be rejected", secret)
+		}
+	}
+}
+
+func TestInitCosmosKeyringWithPrivKeySecret(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr, kb, err := InitCosmosKeyringWithConfig(KeyringConfig{
+		PrivKey: PlainSecret(hex.EncodeToString(privKey.Bytes())),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := kb.KeyByAddress(addr)
+	if err != nil {
+		t.Fatal(err)
+	} else if !info.GetPubKey().Equals(privKey.PubKey()) {
+		t.Fatal("expected the keyring to hold the privkey")
+	}
+
+	if _, _, err := InitCosmosKeyringWithConfig(KeyringConfig{
+		PrivKey:   PlainSecret(hex.EncodeToString(privKey.Bytes())),
+		UseLedger: true,
+	}); err == nil {
+		t.Fatal("expected ledger and privkey options to be rejected together")
+	}
+}
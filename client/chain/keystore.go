@@ -0,0 +1,218 @@
+package chain
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	cosmcrypto "github.com/cosmos/cosmos-sdk/crypto"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+// KeystoreKDF is the key derivation function used to encrypt V3 keystore JSON.
+type KeystoreKDF int
+
+const (
+	// KeystoreScrypt uses scrypt with the standard geth parameters, i.e. 256MB of memory
+	KeystoreScrypt KeystoreKDF = iota
+	// KeystoreScryptLight uses scrypt with the light geth parameters, i.e. 4MB of memory
+	KeystoreScryptLight
+	// KeystorePBKDF2 uses PBKDF2 with HMAC-SHA256
+	KeystorePBKDF2
+)
+
+const (
+	keystoreVersion = 3
+	keystoreCipher  = "aes-128-ctr"
+
+	keystorePBKDF2C     = 262144
+	keystorePBKDF2PRF   = "hmac-sha256"
+	keystoreKDFKeyLen   = 32
+	keystoreKDFSaltSize = 32
+)
+
+// keystoreJSON is the Web3 Secret Storage V3 encoding of an encrypted key.
+type keystoreJSON struct {
+	Address string              `json:"address"`
+	Crypto  keystore.CryptoJSON `json:"crypto"`
+	ID      string              `json:"id"`
+	Version int                 `json:"version"`
+}
+
+// EncryptKeystore encrypts the private key into V3 keystore JSON, as used by geth and MetaMask.
+func EncryptKeystore(privKey *ethsecp256k1.PrivKey, passphrase string, kdf KeystoreKDF) ([]byte, error) {
+	keyBytes := privKey.Bytes()
+
+	var (
+		cryptoJSON keystore.CryptoJSON
+		err        error
+	)
+	switch kdf {
+	case KeystoreScrypt:
+		cryptoJSON, err = keystore.EncryptDataV3(keyBytes, []byte(passphrase), keystore.StandardScryptN, keystore.StandardScryptP)
+	case KeystoreScryptLight:
+		cryptoJSON, err = keystore.EncryptDataV3(keyBytes, []byte(passphrase), keystore.LightScryptN, keystore.LightScryptP)
+	case KeystorePBKDF2:
+		cryptoJSON, err = encryptKeystorePBKDF2(keyBytes, []byte(passphrase))
+	default:
+		err = errors.Errorf("unsupported keystore KDF: %d", kdf)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "failed to encrypt keystore")
+		return nil, err
+	}
+
+	return json.Marshal(keystoreJSON{
+		Address: hex.EncodeToString(privKey.PubKey().Address()),
+		Crypto:  cryptoJSON,
+		ID:      uuid.NewRandom().String(),
+		Version: keystoreVersion,
+	})
+}
+
+// DecryptKeystore decrypts V3 keystore JSON encrypted with either scrypt or PBKDF2 into a private key.
+func DecryptKeystore(keyJSON []byte, passphrase string) (*ethsecp256k1.PrivKey, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decrypt keystore")
+		return nil, err
+	}
+
+	return &ethsecp256k1.PrivKey{
+		Key: ethcrypto.FromECDSA(key.PrivateKey),
+	}, nil
+}
+
+// ImportKeystore decrypts V3 keystore JSON and stores its key in the keyring under the name.
+func ImportKeystore(kb keyring.Keyring, name string, keyJSON []byte, passphrase string) (keyring.Info, error) {
+	privKey, err := DecryptKeystore(keyJSON, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPhrase := randPhrase(64)
+	armored := cosmcrypto.EncryptArmorPrivKey(privKey, tmpPhrase, privKey.Type())
+	if err := kb.ImportPrivKey(name, armored, tmpPhrase); err != nil {
+		err = errors.Wrapf(err, "failed to import keystore key %s", name)
+		return nil, err
+	}
+
+	return kb.Key(name)
+}
+
+// ExportKeystore exports the named eth_secp256k1 key of the keyring as V3 keystore JSON.
+func ExportKeystore(kb keyring.Keyring, name string, passphrase string, kdf KeystoreKDF) ([]byte, error) {
+	tmpPhrase := randPhrase(64)
+	armored, err := kb.ExportPrivKeyArmor(name, tmpPhrase)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to export key %s", name)
+		return nil, err
+	}
+
+	return ArmoredToKeystore(armored, tmpPhrase, passphrase, kdf)
+}
+
+// ArmoredToKeystore converts a cosmos armored eth_secp256k1 private key, e.g. exported with
+// `keys export`, into V3 keystore JSON.
+func ArmoredToKeystore(armored, armorPassphrase, keystorePassphrase string, kdf KeystoreKDF) ([]byte, error) {
+	privKey, _, err := cosmcrypto.UnarmorDecryptPrivKey(armored, armorPassphrase)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decrypt armored key")
+		return nil, err
+	}
+
+	ethPrivKey, err := toEthPrivKey(privKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return EncryptKeystore(ethPrivKey, keystorePassphrase, kdf)
+}
+
+// KeystoreToArmored converts V3 keystore JSON into a cosmos armored private key, e.g. for `keys import`.
+func KeystoreToArmored(keyJSON []byte, keystorePassphrase, armorPassphrase string) (string, error) {
+	privKey, err := DecryptKeystore(keyJSON, keystorePassphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return cosmcrypto.EncryptArmorPrivKey(privKey, armorPassphrase, privKey.Type()), nil
+}
+
+// toEthPrivKey checks that the key is an eth_secp256k1 key, since the keystore address
+// of other keys wouldn't match their cosmos address.
+func toEthPrivKey(privKey cryptotypes.PrivKey) (*ethsecp256k1.PrivKey, error) {
+	ethPrivKey, ok := privKey.(*ethsecp256k1.PrivKey)
+	if !ok {
+		err := errors.Errorf("keystore requires an %s key, got %s", ethsecp256k1.KeyType, privKey.Type())
+		return nil, err
+	}
+
+	return ethPrivKey, nil
+}
+
+// encryptKeystorePBKDF2 encrypts the data like keystore.EncryptDataV3, deriving the key with PBKDF2.
+func encryptKeystorePBKDF2(data, passphrase []byte) (keystore.CryptoJSON, error) {
+	salt := make([]byte, keystoreKDFSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	derivedKey := pbkdf2.Key(passphrase, salt, keystorePBKDF2C, keystoreKDFKeyLen, sha256.New)
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+
+	aesBlock, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return keystore.CryptoJSON{}, err
+	}
+	cipherText := make([]byte, len(data))
+	cipher.NewCTR(aesBlock, iv).XORKeyStream(cipherText, data)
+	mac := ethcrypto.Keccak256(derivedKey[16:32], cipherText)
+
+	cryptoJSON := keystore.CryptoJSON{
+		Cipher:     keystoreCipher,
+		CipherText: hex.EncodeToString(cipherText),
+		KDF:        "pbkdf2",
+		KDFParams: map[string]interface{}{
+			"c":     keystorePBKDF2C,
+			"dklen": keystoreKDFKeyLen,
+			"prf":   keystorePBKDF2PRF,
+			"salt":  hex.EncodeToString(salt),
+		},
+		MAC: hex.EncodeToString(mac),
+	}
+	cryptoJSON.CipherParams.IV = hex.EncodeToString(iv)
+
+	return cryptoJSON, nil
+}
+
+// KeystoreAddress returns the address stored in V3 keystore JSON, without decrypting it.
+func KeystoreAddress(keyJSON []byte) (sdk.AccAddress, error) {
+	var k keystoreJSON
+	if err := json.Unmarshal(keyJSON, &k); err != nil {
+		err = errors.Wrap(err, "failed to parse keystore")
+		return nil, err
+	} else if !ethcommon.IsHexAddress(k.Address) {
+		err = errors.Errorf("invalid keystore address: %s", k.Address)
+		return nil, err
+	}
+
+	return sdk.AccAddress(ethcommon.HexToAddress(k.Address).Bytes()), nil
+}
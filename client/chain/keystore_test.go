@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"encoding/hex"
+	"testing"
+
+	cosmcrypto "github.com/cosmos/cosmos-sdk/crypto"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+	"github.com/gotabit/sdk-go/chain/crypto/hd"
+)
+
+// testKeystorePBKDF2 is the PBKDF2 test vector of the Web3 Secret Storage definition
+const testKeystorePBKDF2 = `{
+  "address": "008aeeda4d805471df9b2a5b0f38a0c3bcba786b",
+  "crypto": {
+    "cipher": "aes-128-ctr",
+    "cipherparams": {"iv": "6087dab2f9fdbbfaddc31a909735c1e6"},
+    "ciphertext": "5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46",
+    "kdf": "pbkdf2",
+    "kdfparams": {
+      "c": 262144,
+      "dklen": 32,
+      "prf": "hmac-sha256",
+      "salt": "ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"
+    },
+    "mac": "517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"
+  },
+  "id": "3198bc9c-6672-5ab3-d995-4942343ae5b6",
+  "version": 3
+}`
+
+func TestDecryptKeystoreVector(t *testing.T) {
+	privKey, err := DecryptKeystore([]byte(testKeystorePBKDF2), "testpassword")
+	if err != nil {
+		t.Fatal(err)
+	} else if hex.EncodeToString(privKey.Bytes()) != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Fatalf("unexpected private key %x", privKey.Bytes())
+	}
+
+	if _, err := DecryptKeystore([]byte(testKeystorePBKDF2), "wrong"); err == nil {
+		t.Fatal("expected a wrong passphrase to be rejected")
+	}
+
+	addr, err := KeystoreAddress([]byte(testKeystorePBKDF2))
+	if err != nil {
+		t.Fatal(err)
+	} else if hex.EncodeToString(addr) != "008aeeda4d805471df9b2a5b0f38a0c3bcba786b" {
+		t.Fatalf("unexpected keystore address %x", addr.Bytes())
+	}
+}
+
+func TestEncryptKeystoreRoundTrip(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the standard scrypt parameters need 256MB of memory
+	for _, kdf := range []KeystoreKDF{KeystoreScryptLight, KeystorePBKDF2} {
+		keyJSON, err := EncryptKeystore(privKey, "passphrase", kdf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decrypted, err := DecryptKeystore(keyJSON, "passphrase")
+		if err != nil {
+			t.Fatalf("kdf %d: %v", kdf, err)
+		} else if !decrypted.Equals(privKey) {
+			t.Fatalf("kdf %d: expected the key to be decrypted", kdf)
+		}
+
+		addr, err := KeystoreAddress(keyJSON)
+		if err != nil {
+			t.Fatal(err)
+		} else if !addr.Equals(sdk.AccAddress(privKey.PubKey().Address())) {
+			t.Fatalf("kdf %d: expected the address of the key, got %s", kdf, addr)
+		}
+	}
+
+	if _, err := EncryptKeystore(privKey, "passphrase", KeystoreKDF(-1)); err == nil {
+		t.Fatal("expected an unknown KDF to be rejected")
+	}
+}
+
+func TestImportExportKeystore(t *testing.T) {
+	kb := keyring.NewInMemory(hd.EthSecp256k1Option())
+
+	info, err := ImportKeystore(kb, "imported", []byte(testKeystorePBKDF2), "testpassword")
+	if err != nil {
+		t.Fatal(err)
+	} else if hex.EncodeToString(info.GetAddress()) != "008aeeda4d805471df9b2a5b0f38a0c3bcba786b" {
+		t.Fatalf("unexpected address of the imported key %x", info.GetAddress().Bytes())
+	}
+
+	keyJSON, err := ExportKeystore(kb, "imported", "exported", KeystoreScryptLight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privKey, err := DecryptKeystore(keyJSON, "exported")
+	if err != nil {
+		t.Fatal(err)
+	} else if hex.EncodeToString(privKey.Bytes()) != "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d" {
+		t.Fatal("expected the exported keystore to hold the imported key")
+	}
+}
+
+func TestKeystoreArmorConversion(t *testing.T) {
+	armored, err := KeystoreToArmored([]byte(testKeystorePBKDF2), "testpassword", "armor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyJSON, err := ArmoredToKeystore(armored, "armor", "passphrase", KeystoreScryptLight)
+	if err != nil {
+		t.Fatal(err)
+	} else if addr, err := KeystoreAddress(keyJSON); err != nil || hex.EncodeToString(addr) != "008aeeda4d805471df9b2a5b0f38a0c3bcba786b" {
+		t.Fatalf("unexpected keystore address %x: %v", addr.Bytes(), err)
+	}
+
+	// the keystore address of a secp256k1 key wouldn't match its cosmos address
+	cosmosKey := secp256k1.GenPrivKey()
+	armored = cosmcrypto.EncryptArmorPrivKey(cosmosKey, "armor", cosmosKey.Type())
+	if _, err := ArmoredToKeystore(armored, "armor", "passphrase", KeystoreScryptLight); err == nil {
+		t.Fatal("expected a secp256k1 key to be rejected")
+	}
+}
@@ -0,0 +1,127 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	chaintypes "github.com/gotabit/sdk-go/chain/types"
+)
+
+// MsgResult is the outcome of a single message that was broadcast as part of a Tx.
+type MsgResult struct {
+	TxHash   string
+	Height   int64
+	Response *chaintypes.TxResponseGenericMessage
+	Err      error
+}
+
+// MsgFuture is a handle for a queued message that resolves once the batch containing
+// the message has been committed, rejected or timed out.
+type MsgFuture struct {
+	once   sync.Once
+	done   chan struct{}
+	result MsgResult
+}
+
+func newMsgFuture() *MsgFuture {
+	return &MsgFuture{
+		done: make(chan struct{}),
+	}
+}
+
+func (f *MsgFuture) resolve(result MsgResult) {
+	f.once.Do(func() {
+		f.result = result
+		close(f.done)
+	})
+}
+
+// Done returns a channel that is closed when the result is available.
+func (f *MsgFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result blocks until the message has been processed and returns its result.
+func (f *MsgFuture) Result() MsgResult {
+	<-f.done
+	return f.result
+}
+
+// Await waits for the result until ctx is done.
+func (f *MsgFuture) Await(ctx context.Context) (MsgResult, error) {
+	select {
+	case <-ctx.Done():
+		return MsgResult{}, ctx.Err()
+	case <-f.done:
+		return f.result, f.result.Err
+	}
+}
+
+type queuedMsg struct {
+	msg    sdk.Msg
+	future *MsgFuture
+}
+
+// resolveBatch delivers the outcome of a batch broadcast to every future in the batch.
+func resolveBatch(batch []queuedMsg, res *sdk.TxResponse, err error) {
+	var txHash string
+	var height int64
+	if res != nil {
+		txHash = res.TxHash
+		height = res.Height
+	}
+
+	if err == nil {
+		err = ParseTxError(res)
+	}
+
+	var responses []*chaintypes.TxResponseGenericMessage
+	if err == nil && res != nil {
+		var decodeErr error
+		if responses, decodeErr = decodeTxResponseData(res.Data); decodeErr != nil {
+			err = decodeErr
+		}
+	}
+
+	for idx, queued := range batch {
+		if queued.future == nil {
+			continue
+		}
+
+		result := MsgResult{
+			TxHash: txHash,
+			Height: height,
+			Err:    err,
+		}
+		if idx < len(responses) {
+			result.Response = responses[idx]
+		}
+
+		queued.future.resolve(result)
+	}
+}
+
+// decodeTxResponseData decodes the hex-encoded TxResponse data into per-message responses.
+func decodeTxResponseData(data string) ([]*chaintypes.TxResponseGenericMessage, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	bz, err := hex.DecodeString(data)
+	if err != nil {
+		err = errors.Wrap(err, "failed to hex-decode tx response data")
+		return nil, err
+	}
+
+	response := chaintypes.TxResponseData{}
+	if err := response.Unmarshal(bz); err != nil {
+		err = errors.Wrap(err, "failed to unmarshal tx response data")
+		return nil, err
+	}
+
+	return response.Messages, nil
+}
@@ -0,0 +1,108 @@
+package chain
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+
+	chaintypes "github.com/gotabit/sdk-go/chain/types"
+)
+
+func newTestBatch(n int) []queuedMsg {
+	batch := make([]queuedMsg, n)
+	for idx := range batch {
+		batch[idx].future = newMsgFuture()
+	}
+
+	return batch
+}
+
+func TestResolveBatchDeliversMsgResponses(t *testing.T) {
+	data, err := (&chaintypes.TxResponseData{
+		Messages: []*chaintypes.TxResponseGenericMessage{
+			{Header: "/cosmos.bank.v1beta1.MsgSend", Data: []byte{1}},
+			{Header: "/cosmos.bank.v1beta1.MsgSend", Data: []byte{2}},
+		},
+	}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := newTestBatch(2)
+	resolveBatch(batch, &sdk.TxResponse{TxHash: "AB", Height: 7, Data: hex.EncodeToString(data)}, nil)
+
+	for idx, queued := range batch {
+		result := queued.future.Result()
+		if result.Err != nil {
+			t.Fatal(result.Err)
+		} else if result.TxHash != "AB" || result.Height != 7 {
+			t.Fatalf("unexpected result %+v", result)
+		} else if result.Response == nil || result.Response.Data[0] != byte(idx+1) {
+			t.Fatalf("expected msg %d to get its own response, got %v", idx, result.Response)
+		}
+	}
+}
+
+func TestResolveBatchDeliversTxError(t *testing.T) {
+	batch := newTestBatch(2)
+	resolveBatch(batch, &sdk.TxResponse{
+		TxHash:    "AB",
+		Codespace: sdkerrors.RootCodespace,
+		Code:      sdkerrors.ErrUnauthorized.ABCICode(),
+		RawLog:    "unauthorized",
+	}, nil)
+
+	for _, queued := range batch {
+		var unauthorizedErr *ErrUnauthorized
+		if result := queued.future.Result(); !errors.As(result.Err, &unauthorizedErr) {
+			t.Fatalf("expected ErrUnauthorized, got %v", result.Err)
+		} else if result.TxHash != "AB" {
+			t.Fatalf("expected the tx hash of the failed tx, got %s", result.TxHash)
+		}
+	}
+
+	broadcastErr := errors.New("connection refused")
+	batch = newTestBatch(1)
+	resolveBatch(batch, nil, broadcastErr)
+	if result := batch[0].future.Result(); result.Err != broadcastErr {
+		t.Fatalf("expected the broadcast error, got %v", result.Err)
+	}
+}
+
+func TestMsgFutureResolvesOnce(t *testing.T) {
+	future := newMsgFuture()
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelFn()
+	if _, err := future.Await(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected the await to time out, got %v", err)
+	}
+
+	future.resolve(MsgResult{TxHash: "first"})
+	future.resolve(MsgResult{TxHash: "second"})
+
+	select {
+	case <-future.Done():
+	default:
+		t.Fatal("expected the future to be done")
+	}
+
+	if result, err := future.Await(context.Background()); err != nil || result.TxHash != "first" {
+		t.Fatalf("expected the first result, got %+v %v", result, err)
+	}
+}
+
+func TestDecodeTxResponseDataRejectsInvalidData(t *testing.T) {
+	if responses, err := decodeTxResponseData(""); err != nil || responses != nil {
+		t.Fatalf("expected no responses for empty data, got %v %v", responses, err)
+	} else if _, err := decodeTxResponseData("zz"); err == nil {
+		t.Fatal("expected invalid hex to be rejected")
+	} else if _, err := decodeTxResponseData("ff"); err == nil {
+		t.Fatal("expected invalid proto to be rejected")
+	}
+}
@@ -0,0 +1,182 @@
+package chain
+
+import (
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"github.com/cosmos/cosmos-sdk/crypto/types/multisig"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+)
+
+// multisigSignMode is the only sign mode multisig members can use, since the direct sign bytes
+// would include the combined signer info that isn't known until all members have signed.
+const multisigSignMode = signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+
+// NewMultisigPubKey creates a threshold multisig pubkey from the member pubkeys, which may be
+// a mix of ethsecp256k1 and secp256k1 keys. The order of the pubkeys defines the multisig address.
+func NewMultisigPubKey(threshold int, pubKeys []cryptotypes.PubKey) (*kmultisig.LegacyAminoPubKey, error) {
+	if threshold <= 0 {
+		return nil, errors.Errorf("multisig threshold must be positive, got %d", threshold)
+	} else if len(pubKeys) < threshold {
+		return nil, errors.Errorf("multisig threshold %d exceeds the number of pubkeys %d", threshold, len(pubKeys))
+	}
+
+	return kmultisig.NewLegacyAminoPubKey(threshold, pubKeys), nil
+}
+
+// SaveMultisigKey stores a multisig key reference made of the member pubkeys in the keyring.
+func SaveMultisigKey(kb keyring.Keyring, name string, threshold int, pubKeys []cryptotypes.PubKey) (keyring.Info, error) {
+	multisigPubKey, err := NewMultisigPubKey(threshold, pubKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := kb.SaveMultisig(name, multisigPubKey)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to save multisig key %s", name)
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// SignMultisigPartialWithKeyring signs the Tx as a member of the multisig with the named key of the keyring.
+// The TxConfig must have SIGN_MODE_LEGACY_AMINO_JSON enabled. Partial signatures can be exported
+// with TxConfig.MarshalSignatureJSON to be combined elsewhere.
+func (o *OfflineTx) SignMultisigPartialWithKeyring(
+	txConfig client.TxConfig,
+	kb keyring.Keyring,
+	keyName string,
+) (signingtypes.SignatureV2, error) {
+	signBytes, err := o.multisigSignBytes(txConfig)
+	if err != nil {
+		return signingtypes.SignatureV2{}, err
+	}
+
+	sigBytes, pubKey, err := kb.Sign(keyName, signBytes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to sign multisig tx")
+		return signingtypes.SignatureV2{}, err
+	}
+
+	return o.partialSignature(pubKey, sigBytes), nil
+}
+
+// SignMultisigPartialWithPrivKey signs the Tx as a member of the multisig with a raw private key.
+// The TxConfig must have SIGN_MODE_LEGACY_AMINO_JSON enabled.
+func (o *OfflineTx) SignMultisigPartialWithPrivKey(
+	txConfig client.TxConfig,
+	privKey cryptotypes.PrivKey,
+) (signingtypes.SignatureV2, error) {
+	signBytes, err := o.multisigSignBytes(txConfig)
+	if err != nil {
+		return signingtypes.SignatureV2{}, err
+	}
+
+	sigBytes, err := privKey.Sign(signBytes)
+	if err != nil {
+		err = errors.Wrap(err, "failed to sign multisig tx")
+		return signingtypes.SignatureV2{}, err
+	}
+
+	return o.partialSignature(privKey.PubKey(), sigBytes), nil
+}
+
+// CombineMultisig combines the partial signatures of the members into the multisig signature
+// and returns the encoded signed Tx, ready for BroadcastSignedTx. Repeated signatures of the same
+// member only count once towards the threshold.
+func (o *OfflineTx) CombineMultisig(
+	txConfig client.TxConfig,
+	multisigPubKey *kmultisig.LegacyAminoPubKey,
+	partialSigs ...signingtypes.SignatureV2,
+) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	signBytes, err := o.multisigSignBytes(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := make(map[string]bool, len(partialSigs))
+	memberSigs := make([]signingtypes.SignatureV2, 0, len(partialSigs))
+	for _, sig := range partialSigs {
+		single, ok := sig.Data.(*signingtypes.SingleSignatureData)
+		if !ok {
+			return nil, errors.New("partial signature must be a single signature")
+		} else if !sig.PubKey.VerifySignature(signBytes, single.Signature) {
+			err := errors.Errorf("invalid partial signature of %s", sig.PubKey.Address())
+			return nil, err
+		}
+
+		member := string(sig.PubKey.Bytes())
+		if signed[member] {
+			continue
+		}
+		signed[member] = true
+		memberSigs = append(memberSigs, sig)
+	}
+
+	if uint(len(memberSigs)) < multisigPubKey.GetThreshold() {
+		err := errors.Errorf("multisig requires %d signatures, got %d", multisigPubKey.GetThreshold(), len(memberSigs))
+		return nil, err
+	}
+
+	pubKeys := multisigPubKey.GetPubKeys()
+	multisigSig := multisig.NewMultisig(len(pubKeys))
+	for _, sig := range memberSigs {
+		if err := multisig.AddSignatureV2(multisigSig, sig, pubKeys); err != nil {
+			err = errors.Wrap(err, "failed to add partial signature")
+			return nil, err
+		}
+	}
+
+	sig := signingtypes.SignatureV2{
+		PubKey:   multisigPubKey,
+		Data:     multisigSig,
+		Sequence: o.Sequence,
+	}
+	if err := txn.SetSignatures(sig); err != nil {
+		err = errors.Wrap(err, "failed to set multisig signature")
+		return nil, err
+	}
+
+	return encodeTx(txConfig, txn)
+}
+
+func (o *OfflineTx) multisigSignBytes(txConfig client.TxConfig) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	signerData := authsigning.SignerData{
+		ChainID:       o.ChainID,
+		AccountNumber: o.AccountNumber,
+		Sequence:      o.Sequence,
+	}
+
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(multisigSignMode, signerData, txn.GetTx())
+	if err != nil {
+		err = errors.Wrap(err, "failed to get multisig sign bytes")
+		return nil, err
+	}
+
+	return signBytes, nil
+}
+
+func (o *OfflineTx) partialSignature(pubKey cryptotypes.PubKey, sigBytes []byte) signingtypes.SignatureV2 {
+	return signingtypes.SignatureV2{
+		PubKey: pubKey,
+		Data: &signingtypes.SingleSignatureData{
+			SignMode:  multisigSignMode,
+			Signature: sigBytes,
+		},
+		Sequence: o.Sequence,
+	}
+}
@@ -0,0 +1,108 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	kmultisig "github.com/cosmos/cosmos-sdk/crypto/keys/multisig"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+func newTestMultisigTx(t *testing.T, threshold, members int) (client.TxConfig, *OfflineTx, []cryptotypes.PrivKey, *kmultisig.LegacyAminoPubKey) {
+	t.Helper()
+
+	privKeys := make([]cryptotypes.PrivKey, 0, members)
+	pubKeys := make([]cryptotypes.PubKey, 0, members)
+	for i := 0; i < members; i++ {
+		privKey := secp256k1.GenPrivKey()
+		privKeys = append(privKeys, privKey)
+		pubKeys = append(pubKeys, privKey.PubKey())
+	}
+
+	multisigPubKey, err := NewMultisigPubKey(threshold, pubKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from := sdk.AccAddress(multisigPubKey.Address())
+	msg := &banktypes.MsgSend{
+		FromAddress: from.String(),
+		ToAddress:   from.String(),
+		Amount:      sdk.NewCoins(sdk.NewInt64Coin("aaa", 1)),
+	}
+
+	txConfig := NewTxConfig([]signingtypes.SignMode{signingtypes.SignMode_SIGN_MODE_DIRECT, multisigSignMode})
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed}, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return txConfig, offlineTx, privKeys, multisigPubKey
+}
+
+func TestCombineMultisigCountsMembersOnce(t *testing.T) {
+	txConfig, offlineTx, privKeys, multisigPubKey := newTestMultisigTx(t, 2, 3)
+
+	partialSigs := make([]signingtypes.SignatureV2, 0, len(privKeys))
+	for _, privKey := range privKeys[:2] {
+		sig, err := offlineTx.SignMultisigPartialWithPrivKey(txConfig, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		partialSigs = append(partialSigs, sig)
+	}
+
+	// the same member can't reach the threshold alone
+	if _, err := offlineTx.CombineMultisig(txConfig, multisigPubKey, partialSigs[0], partialSigs[0]); err == nil {
+		t.Fatal("expected a repeated partial signature to count once")
+	}
+
+	txBytes, err := offlineTx.CombineMultisig(txConfig, multisigPubKey, partialSigs[0], partialSigs[0], partialSigs[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs, err := decoded.(authsigning.SigVerifiableTx).GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	multiSig, ok := sigs[0].Data.(*signingtypes.MultiSignatureData)
+	if !ok {
+		t.Fatalf("expected a multisig signature, got %T", sigs[0].Data)
+	} else if len(multiSig.Signatures) != 2 {
+		t.Fatalf("expected 2 member signatures, got %d", len(multiSig.Signatures))
+	}
+}
+
+func TestCombineMultisigRejectsInvalidSignatures(t *testing.T) {
+	txConfig, offlineTx, privKeys, multisigPubKey := newTestMultisigTx(t, 1, 2)
+
+	sig, err := offlineTx.SignMultisigPartialWithPrivKey(txConfig, privKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// signed by another member key
+	forged := sig
+	forged.PubKey = privKeys[1].PubKey()
+	if _, err := offlineTx.CombineMultisig(txConfig, multisigPubKey, forged); err == nil {
+		t.Fatal("expected the signature of another key to be rejected")
+	}
+
+	outsider, err := offlineTx.SignMultisigPartialWithPrivKey(txConfig, secp256k1.GenPrivKey())
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err := offlineTx.CombineMultisig(txConfig, multisigPubKey, outsider); err == nil {
+		t.Fatal("expected the signature of a non-member to be rejected")
+	}
+}
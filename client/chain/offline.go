@@ -0,0 +1,274 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// OfflineTxParams are the Tx parameters that a connected client would query from the chain
+// or estimate by simulation. They must be provided explicitly to build a Tx offline.
+type OfflineTxParams struct {
+	ChainID       string
+	AccountNumber uint64
+	Sequence      uint64
+	Gas           uint64
+	Fees          string
+	Memo          string
+	TimeoutHeight uint64
+	FeeGranter    sdk.AccAddress
+}
+
+// OfflineTx is an unsigned Tx along with the signer data required to sign it
+// on a machine without network access.
+type OfflineTx struct {
+	ChainID       string          `json:"chain_id"`
+	AccountNumber uint64          `json:"account_number"`
+	Sequence      uint64          `json:"sequence"`
+	Tx            json.RawMessage `json:"tx"`
+}
+
+// NewOfflineTx builds an unsigned Tx from msgs without querying the chain.
+func NewOfflineTx(txConfig client.TxConfig, params OfflineTxParams, msgs ...sdk.Msg) (*OfflineTx, error) {
+	if params.ChainID == "" {
+		return nil, errors.New("chain ID is required to build an offline tx")
+	} else if params.Gas == 0 {
+		return nil, errors.New("gas limit is required to build an offline tx")
+	}
+
+	txf := offlineTxFactory(txConfig, params.ChainID, params.AccountNumber, params.Sequence).
+		WithGas(params.Gas).
+		WithFees(params.Fees).
+		WithMemo(params.Memo).
+		WithTimeoutHeight(params.TimeoutHeight)
+
+	txn, err := tx.BuildUnsignedTx(txf, msgs...)
+	if err != nil {
+		err = errors.Wrap(err, "failed to BuildUnsignedTx")
+		return nil, err
+	}
+	txn.SetFeeGranter(params.FeeGranter)
+
+	txJSON, err := txConfig.TxJSONEncoder()(txn.GetTx())
+	if err != nil {
+		err = errors.Wrap(err, "failed to encode tx JSON")
+		return nil, err
+	}
+
+	return &OfflineTx{
+		ChainID:       params.ChainID,
+		AccountNumber: params.AccountNumber,
+		Sequence:      params.Sequence,
+		Tx:            txJSON,
+	}, nil
+}
+
+// DecodeOfflineTx decodes an OfflineTx exported as JSON.
+func DecodeOfflineTx(bz []byte) (*OfflineTx, error) {
+	offlineTx := &OfflineTx{}
+	if err := json.Unmarshal(bz, offlineTx); err != nil {
+		err = errors.Wrap(err, "failed to unmarshal offline tx")
+		return nil, err
+	}
+
+	return offlineTx, nil
+}
+
+// TxBuilder decodes the wrapped Tx into a builder, e.g. to inspect or amend it before signing.
+func (o *OfflineTx) TxBuilder(txConfig client.TxConfig) (client.TxBuilder, error) {
+	txn, err := txConfig.TxJSONDecoder()(o.Tx)
+	if err != nil {
+		err = errors.Wrap(err, "failed to decode tx JSON")
+		return nil, err
+	}
+
+	builder, err := txConfig.WrapTxBuilder(txn)
+	if err != nil {
+		err = errors.Wrap(err, "failed to WrapTxBuilder")
+		return nil, err
+	}
+
+	return builder, nil
+}
+
+// TxBytes returns the protobuf encoding of the wrapped Tx, signed or not.
+func (o *OfflineTx) TxBytes(txConfig client.TxConfig) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeTx(txConfig, txn)
+}
+
+// SignWithKeyring signs the Tx with the named key of the keyring and returns the encoded signed Tx.
+func (o *OfflineTx) SignWithKeyring(txConfig client.TxConfig, kb keyring.Keyring, keyName string) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	txf := offlineTxFactory(txConfig, o.ChainID, o.AccountNumber, o.Sequence).WithKeybase(kb)
+	if err := tx.Sign(txf, keyName, txn, true); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return nil, err
+	}
+
+	return encodeTx(txConfig, txn)
+}
+
+// SignWithPrivKey signs the Tx with a raw private key, e.g. an ethsecp256k1.PrivKey,
+// and returns the encoded signed Tx.
+func (o *OfflineTx) SignWithPrivKey(txConfig client.TxConfig, privKey cryptotypes.PrivKey) ([]byte, error) {
+	return o.SignWithSigner(context.Background(), txConfig, NewPrivKeySigner(privKey))
+}
+
+// SignWithExternalSigner signs the Tx of a watch-only key with an external signer
+// and returns the encoded signed Tx. The returned signature is verified against pubKey.
+func (o *OfflineTx) SignWithExternalSigner(
+	ctx context.Context,
+	txConfig client.TxConfig,
+	pubKey cryptotypes.PubKey,
+	signer ExternalSigner,
+) ([]byte, error) {
+	return o.SignWithSigner(ctx, txConfig, &externalSigner{
+		pubKey: pubKey,
+		signer: signer,
+	})
+}
+
+// SignWithSigner signs the Tx with the signer and returns the encoded signed Tx.
+func (o *OfflineTx) SignWithSigner(ctx context.Context, txConfig client.TxConfig, signer Signer) ([]byte, error) {
+	txn, err := o.TxBuilder(txConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	signerData := authsigning.SignerData{
+		ChainID:       o.ChainID,
+		AccountNumber: o.AccountNumber,
+		Sequence:      o.Sequence,
+	}
+	// no client chain config is known here, addresses get the prefix of the sdk config
+	chainConfig := common.ChainConfig{Bech32Prefix: sdk.GetConfig().GetBech32AccountAddrPrefix()}
+	if err := signTx(ctx, chainConfig, txConfig, txn, signerData, signer); err != nil {
+		err = errors.Wrap(err, "failed to Sign Tx")
+		return nil, err
+	}
+
+	return encodeTx(txConfig, txn)
+}
+
+func encodeTx(txConfig client.TxConfig, txn client.TxBuilder) ([]byte, error) {
+	txBytes, err := txConfig.TxEncoder()(txn.GetTx())
+	if err != nil {
+		err = errors.Wrap(err, "failed TxEncoder to encode Tx")
+		return nil, err
+	}
+
+	return txBytes, nil
+}
+
+func offlineTxFactory(txConfig client.TxConfig, chainID string, accNum, accSeq uint64) tx.Factory {
+	return tx.Factory{}.
+		WithTxConfig(txConfig).
+		WithChainID(chainID).
+		WithAccountNumber(accNum).
+		WithSequence(accSeq).
+		WithSignMode(txConfig.SignModeHandler().DefaultMode())
+}
+
+// offlineConn fails the calls of offline clients, which have no node to connect to.
+type offlineConn struct{}
+
+func (offlineConn) Invoke(ctx context.Context, method string, args interface{}, reply interface{}, opts ...grpc.CallOption) error {
+	return errors.Wrap(ErrOffline, method)
+}
+
+func (offlineConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.Wrap(ErrOffline, method)
+}
+
+// ExternalSigner signs on behalf of a watch-only key, e.g. an HSM, a remote signer or a separate process.
+type ExternalSigner interface {
+	// Sign returns the signature of signBytes by the private key of pubKey.
+	Sign(ctx context.Context, pubKey cryptotypes.PubKey, signBytes []byte) ([]byte, error)
+}
+
+// BuildUnsignedTx builds a Tx for the from key of the client, to be signed elsewhere and broadcast
+// with BroadcastSignedTx. It works for watch-only clients with an offline or multisig key.
+// The account number, sequence, gas and fees are resolved from chain, unless overridden.
+// Multisig Txs can't be simulated, so their gas must be overridden. Clients created with
+// common.OptionOffline resolve nothing from chain, the account number, sequence and gas
+// are taken from overrides and the fees from the configured gas prices.
+func (c *chainClient) BuildUnsignedTx(ctx context.Context, overrides TxOverrides, msgs ...sdk.Msg) (*OfflineTx, error) {
+	from := c.ctx.GetFromAddress()
+	if from.Empty() {
+		return nil, errors.New("client has no from key to build tx for")
+	}
+
+	clientCtx, txf, err := overrides.apply(c.ctx, c.txFactory)
+	if err != nil {
+		return nil, err
+	}
+	txf = txf.WithAccountNumber(overrides.AccountNumber).WithSequence(overrides.Sequence)
+
+	if !clientCtx.Offline {
+		if txf, err = c.prepareFactory(ctx, clientCtx, txf); err != nil {
+			err = errors.Wrap(err, "failed to get account num and seq")
+			return nil, err
+		}
+	}
+	accNum, accSeq := txf.AccountNumber(), txf.Sequence()
+
+	if clientCtx.Simulate {
+		adjustedGas, err := c.simulateGas(ctx, txf, c.fromPubKey(), msgs)
+		if err != nil {
+			return nil, err
+		}
+		txf = txf.WithGas(adjustedGas)
+	}
+
+	if txf, err = c.resolveFees(ctx, txf); err != nil {
+		return nil, err
+	}
+
+	params := OfflineTxParams{
+		ChainID:       txf.ChainID(),
+		AccountNumber: accNum,
+		Sequence:      accSeq,
+		Gas:           txf.Gas(),
+		Fees:          txf.Fees().String(),
+		Memo:          txf.Memo(),
+		TimeoutHeight: txf.TimeoutHeight(),
+		FeeGranter:    clientCtx.GetFeeGranterAddress(),
+	}
+
+	return NewOfflineTx(clientCtx.TxConfig, params, msgs...)
+}
+
+// BroadcastSignedTx broadcasts a Tx that has been signed elsewhere, e.g. with OfflineTx.
+// BROADCAST_MODE_BLOCK waits until the Tx is included in block.
+func (c *chainClient) BroadcastSignedTx(
+	ctx context.Context,
+	txBytes []byte,
+	mode txtypes.BroadcastMode,
+) (*txtypes.BroadcastTxResponse, error) {
+	res, err := c.broadcastTxBytes(ctx, c.ctx, txBytes, mode)
+	if err != nil {
+		return res, err
+	}
+
+	return res, ParseTxError(res.TxResponse)
+}
@@ -0,0 +1,191 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func TestOfflineTxRoundTrip(t *testing.T) {
+	signer := newTestSigner().(*privKeySigner)
+	granter := sdk.AccAddress(secp256k1.GenPrivKey().PubKey().Address())
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{
+		ChainID:       "test-1",
+		AccountNumber: 7,
+		Sequence:      3,
+		Gas:           fakeGasUsed,
+		Fees:          "10aaa",
+		Memo:          "offline",
+		TimeoutHeight: 500,
+		FeeGranter:    granter,
+	}, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unsigned tx is carried to the signing machine as JSON
+	bz, err := json.Marshal(offlineTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeOfflineTx(bz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBytes, err := decoded.SignWithPrivKey(txConfig, signer.privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sigTx := txn.(authsigning.Tx)
+	if sigTx.GetMemo() != "offline" || sigTx.GetGas() != fakeGasUsed || sigTx.GetFee().String() != "10aaa" {
+		t.Fatalf("unexpected tx params %s %d %s", sigTx.GetMemo(), sigTx.GetGas(), sigTx.GetFee())
+	} else if sigTx.GetTimeoutHeight() != 500 || !sigTx.FeeGranter().Equals(granter) {
+		t.Fatalf("unexpected timeout height %d or fee granter %s", sigTx.GetTimeoutHeight(), sigTx.FeeGranter())
+	}
+
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(sigs) != 1 || sigs[0].Sequence != 3 || !sigs[0].PubKey.Equals(signer.PubKey()) {
+		t.Fatalf("unexpected signatures %+v", sigs)
+	}
+
+	signerData := authsigning.SignerData{ChainID: "test-1", AccountNumber: 7, Sequence: 3}
+	if err := authsigning.VerifySignature(signer.PubKey(), signerData, sigs[0].Data, txConfig.SignModeHandler(), sigTx); err != nil {
+		t.Fatalf("expected a valid signature: %v", err)
+	}
+}
+
+func TestNewOfflineTxRequiresParams(t *testing.T) {
+	signer := newTestSigner()
+	txConfig := newFakeChain().txConfig
+
+	if _, err := NewOfflineTx(txConfig, OfflineTxParams{Gas: fakeGasUsed}, newTestMsgSend(signer)); err == nil {
+		t.Fatal("expected the chain ID to be required")
+	} else if _, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1"}, newTestMsgSend(signer)); err == nil {
+		t.Fatal("expected the gas limit to be required")
+	} else if _, err := DecodeOfflineTx([]byte("{")); err == nil {
+		t.Fatal("expected invalid JSON to be rejected")
+	}
+}
+
+func TestWatchOnlyClientBuildsTxSignedElsewhere(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	// the watch-only machine only knows the pubkey of the account
+	privKey := secp256k1.GenPrivKey()
+	kb := keyring.NewInMemory()
+	if _, err := kb.SavePubKey("offline", privKey.PubKey(), hd.Secp256k1Type); err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx, err := NewClientContext("test-1", "offline", kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := newTestChainClientWithContext(t, clientCtx, nil, []*fakeNode{a}, common.OptionGasPrices("1aaa"))
+
+	signer := NewPrivKeySigner(privKey)
+	offlineTx, err := c.BuildUnsignedTx(context.Background(), TxOverrides{}, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if offlineTx.ChainID != "test-1" || offlineTx.AccountNumber != chain.account(signer.Address()).num {
+		t.Fatalf("expected the account number to be queried, got %+v", offlineTx)
+	}
+
+	txBytes, err := offlineTx.SignWithPrivKey(clientCtx.TxConfig, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.BroadcastSignedTx(context.Background(), txBytes, txtypes.BroadcastMode_BROADCAST_MODE_BLOCK)
+	if err != nil {
+		t.Fatal(err)
+	} else if res.TxResponse.Height == 0 {
+		t.Fatal("expected the tx to be included in block")
+	}
+
+	// a rebroadcast of the same tx resolves to the included one
+	dupRes, err := c.BroadcastSignedTx(context.Background(), txBytes, txtypes.BroadcastMode_BROADCAST_MODE_SYNC)
+	if err != nil {
+		t.Fatal(err)
+	} else if dupRes.TxResponse.TxHash != res.TxResponse.TxHash || dupRes.TxResponse.Height != res.TxResponse.Height {
+		t.Fatalf("expected the included tx, got %+v", dupRes.TxResponse)
+	}
+}
+
+func TestOfflineClientBuildsTxWithoutNode(t *testing.T) {
+	privKey := secp256k1.GenPrivKey()
+	kb := keyring.NewInMemory()
+	if _, err := kb.SavePubKey("offline", privKey.PubKey(), hd.Secp256k1Type); err != nil {
+		t.Fatal(err)
+	}
+
+	clientCtx, err := NewClientContext("test-1", "offline", kb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// no node address, nothing is dialed
+	c, err := NewChainClient(clientCtx, "", common.OptionOffline(), common.OptionGasPrices("1aaa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if ctx := c.ClientContext(); !ctx.Offline || !ctx.GenerateOnly || ctx.Simulate {
+		t.Fatalf("expected an offline generate-only context, got offline %v generate-only %v simulate %v", ctx.Offline, ctx.GenerateOnly, ctx.Simulate)
+	} else if c.CanSignTransactions() {
+		t.Fatal("expected offline clients to be read-only")
+	}
+
+	signer := NewPrivKeySigner(privKey)
+	if _, err := c.BuildUnsignedTx(context.Background(), TxOverrides{}, newTestMsgSend(signer)); err == nil {
+		t.Fatal("expected the gas limit to be required offline")
+	}
+
+	overrides := TxOverrides{Gas: fakeGasUsed, AccountNumber: 7, Sequence: 0}
+	offlineTx, err := c.BuildUnsignedTx(context.Background(), overrides, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if offlineTx.AccountNumber != 7 || offlineTx.Sequence != 0 {
+		t.Fatalf("expected the account number and sequence of the overrides, got %+v", offlineTx)
+	}
+
+	txBytes, err := offlineTx.SignWithPrivKey(clientCtx.TxConfig, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txn, err := clientCtx.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		t.Fatal(err)
+	} else if fee := txn.(authsigning.Tx).GetFee(); fee.String() != "100000aaa" {
+		t.Fatalf("expected the fee of the configured gas prices, got %s", fee)
+	}
+
+	if _, err := c.BroadcastSignedTx(context.Background(), txBytes, txtypes.BroadcastMode_BROADCAST_MODE_SYNC); errors.Cause(err) != ErrOffline {
+		t.Fatalf("expected ErrOffline, got %v", err)
+	}
+}
@@ -0,0 +1,373 @@
+package chain
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/InjectiveLabs/suplog"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type pendingTx struct {
+	batch    []queuedMsg
+	seq      uint64
+	txHash   string
+	txBytes  []byte
+	cancelFn context.CancelFunc
+}
+
+// txOutcome is the result of awaiting the inclusion of a pending Tx.
+type txOutcome struct {
+	ptx *pendingTx
+	res *sdk.TxResponse
+	err error
+}
+
+// txPipeline broadcasts Txs back-to-back in SYNC mode using an optimistically tracked
+// local sequence, while a separate confirmation loop watches for their inclusion.
+// When a Tx that passed CheckTx is not included within the broadcast timeout and the
+// node doesn't know it anymore, the sequence is rolled back and all the later Txs are
+// re-signed and broadcast again.
+type txPipeline struct {
+	c      *chainClient
+	logger log.Logger
+
+	mux     sync.Mutex
+	pending []*pendingTx
+	closed  bool
+
+	outcomeC chan txOutcome
+	quitC    chan struct{}
+	doneC    chan struct{}
+}
+
+func newTxPipeline(c *chainClient) *txPipeline {
+	return &txPipeline{
+		c: c,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "txPipeline",
+		}),
+		outcomeC: make(chan txOutcome),
+		quitC:    make(chan struct{}),
+		doneC:    make(chan struct{}),
+	}
+}
+
+// submit signs and broadcasts the batch with the next local sequence without waiting for inclusion.
+// Must be called with c.syncMux held.
+func (p *txPipeline) submit(batch []queuedMsg) error {
+	c := p.c
+
+	if p.isClosed() {
+		resolveBatch(batch, nil, ErrQueueClosed)
+		return ErrQueueClosed
+	}
+
+	msgs := make([]sdk.Msg, 0, len(batch))
+	for _, queued := range batch {
+		msgs = append(msgs, queued.msg)
+	}
+
+	txf := c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+	txBytes, res, err := c.signAndBroadcastTx(context.Background(), c.ctx, txf, c.signer, txtypes.BroadcastMode_BROADCAST_MODE_SYNC, msgs...)
+	if isSequenceMismatch(res, err) {
+		c.syncNonce()
+		txf = c.txFactory.WithSequence(c.accSeq).WithAccountNumber(c.accNum)
+		log.Debugln("retrying broadcastTx with nonce", c.accSeq)
+		txBytes, res, err = c.signAndBroadcastTx(context.Background(), c.ctx, txf, c.signer, txtypes.BroadcastMode_BROADCAST_MODE_SYNC, msgs...)
+	}
+
+	if err != nil {
+		p.logger.WithField("size", len(msgs)).WithError(err).Errorln("failed to broadcast msg batch")
+		resolveBatch(batch, res.GetTxResponse(), err)
+		return err
+	} else if err = ParseTxError(res.TxResponse); err != nil && !isSequenceConsumed(res.TxResponse) {
+		// rejected by CheckTx, the sequence was not consumed
+		p.logger.WithField("txHash", res.TxResponse.TxHash).WithError(err).Errorln("failed to broadcast msg batch")
+		resolveBatch(batch, res.TxResponse, err)
+		return err
+	}
+
+	ptx := &pendingTx{
+		batch:   batch,
+		seq:     c.accSeq,
+		txHash:  res.TxResponse.TxHash,
+		txBytes: txBytes,
+	}
+
+	p.mux.Lock()
+	closed := p.closed
+	if !closed {
+		p.pending = append(p.pending, ptx)
+	}
+	p.mux.Unlock()
+
+	c.accSeq++
+	log.Debugln("nonce incremented to", c.accSeq)
+
+	if closed {
+		// the pipeline was closed while broadcasting, nothing awaits the Tx anymore
+		err := errors.Wrapf(ErrTxInFlight, "%s", ptx.txHash)
+		resolveBatch(batch, &sdk.TxResponse{TxHash: ptx.txHash}, err)
+		return err
+	}
+
+	if res.TxResponse.Height > 0 {
+		// an earlier broadcast of the same Tx has already been included
+		go p.report(txOutcome{ptx: ptx, res: res.TxResponse})
+		return nil
+	}
+
+	p.await(ptx)
+	return nil
+}
+
+// await watches for the inclusion of the pending Tx until the broadcast timeout, using
+// the Tx event subscriptions of the client, and reports the outcome to the run loop.
+// Events of Txs included before subscribing are picked up by polling.
+func (p *txPipeline) await(ptx *pendingTx) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), p.c.opts.BroadcastTimeout)
+
+	p.mux.Lock()
+	ptx.cancelFn = cancelFn
+	p.mux.Unlock()
+
+	resultC, unsubscribe := p.c.subscribeTx(ctx, ptx.txHash)
+
+	go func() {
+		defer cancelFn()
+		defer unsubscribe()
+
+		res, err := p.c.awaitTx(ctx, ptx.txHash, resultC)
+		p.report(txOutcome{ptx: ptx, res: res, err: err})
+	}()
+}
+
+func (p *txPipeline) report(outcome txOutcome) {
+	select {
+	case p.outcomeC <- outcome:
+	case <-p.quitC:
+	}
+}
+
+// run is the confirmation loop, it resolves pending Txs as they get included in blocks.
+func (p *txPipeline) run() {
+	defer close(p.doneC)
+
+	for {
+		select {
+		case <-p.quitC:
+			p.mux.Lock()
+			pending := p.pending
+			p.pending = nil
+			for _, ptx := range pending {
+				if ptx.cancelFn != nil {
+					ptx.cancelFn()
+				}
+			}
+			p.mux.Unlock()
+
+			// the Txs have been accepted by the node and may still be included
+			for _, ptx := range pending {
+				err := errors.Wrapf(ErrTxInFlight, "%s", ptx.txHash)
+				resolveBatch(ptx.batch, &sdk.TxResponse{TxHash: ptx.txHash}, err)
+			}
+			return
+		case outcome := <-p.outcomeC:
+			if !p.isPending(outcome.ptx) {
+				// re-signed by a rollback
+				continue
+			}
+
+			if outcome.err == nil {
+				p.c.syncMux.Lock()
+				p.commit(outcome.ptx, outcome.res)
+				p.c.syncMux.Unlock()
+				continue
+			}
+
+			p.recheck(outcome.ptx)
+		}
+	}
+}
+
+// commit resolves the included Tx, or retries its msgs in halves if the batch ran out of gas.
+// Must be called with c.syncMux held.
+func (p *txPipeline) commit(ptx *pendingTx, res *sdk.TxResponse) {
+	p.remove(ptx)
+
+	if isOutOfGas(res) && len(ptx.batch) > 1 {
+		p.logger.WithField("txHash", ptx.txHash).Warningln("msg batch ran out of gas, retrying in halves of", len(ptx.batch)/2)
+
+		half := len(ptx.batch) / 2
+		_ = p.submit(ptx.batch[:half])
+		_ = p.submit(ptx.batch[half:])
+		return
+	}
+
+	resolveBatch(ptx.batch, res, nil)
+	log.WithField("txHash", ptx.txHash).Debugln("msg batch committed successfully at height", res.Height)
+}
+
+// recheck finds out what happened to a Tx that was not seen included within the broadcast
+// timeout. The Tx may have been included without its event, or still sit in the mempool,
+// so the same Tx bytes are broadcast again: the node tells if it already has the Tx, and
+// accepts it again if it has been dropped in the meantime. Only a Tx the node now
+// rejects has been dropped for good.
+func (p *txPipeline) recheck(ptx *pendingTx) {
+	ctx, cancelFn := context.WithTimeout(context.Background(), p.c.opts.BroadcastTimeout)
+	defer cancelFn()
+
+	res, err := p.c.broadcastTxBytes(ctx, p.c.ctx, ptx.txBytes, txtypes.BroadcastMode_BROADCAST_MODE_SYNC)
+	switch {
+	case err != nil:
+		// whether the Tx is still pending is unknown, keep waiting for it
+		p.logger.WithField("txHash", ptx.txHash).WithError(err).Warningln("failed to recheck pending tx")
+		p.await(ptx)
+	case res.TxResponse.Height > 0:
+		p.c.syncMux.Lock()
+		p.commit(ptx, res.TxResponse)
+		p.c.syncMux.Unlock()
+	case res.TxResponse.Code == 0:
+		p.logger.WithField("txHash", ptx.txHash).Debugln("tx is still pending, waiting for it again")
+		p.await(ptx)
+	default:
+		dropErr := ParseTxError(res.TxResponse)
+
+		var seqErr *ErrSequenceMismatch
+		if errors.As(dropErr, &seqErr) && seqErr.Expected < ptx.seq && !p.isFirstPending(ptx) {
+			// an earlier Tx is missing, its recheck decides about the rollback
+			p.await(ptx)
+			return
+		}
+
+		p.rollback(ptx, dropErr)
+	}
+}
+
+func (p *txPipeline) isFirstPending(ptx *pendingTx) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return len(p.pending) > 0 && p.pending[0] == ptx
+}
+
+func (p *txPipeline) isPending(ptx *pendingTx) bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for _, pending := range p.pending {
+		if pending == ptx {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *txPipeline) remove(ptx *pendingTx) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for idx, pending := range p.pending {
+		if pending == ptx {
+			p.pending = append(p.pending[:idx], p.pending[idx+1:]...)
+			return
+		}
+	}
+}
+
+// rollback fails the dropped Tx, resets the local sequence to the one the chain expects
+// and re-signs every later pending Tx, since they can't be included with a sequence gap.
+func (p *txPipeline) rollback(dropped *pendingTx, dropErr error) {
+	p.c.syncMux.Lock()
+	defer p.c.syncMux.Unlock()
+
+	p.mux.Lock()
+	var affected []*pendingTx
+	for idx, pending := range p.pending {
+		if pending == dropped {
+			affected = append(affected, p.pending[idx+1:]...)
+			p.pending = p.pending[:idx]
+			break
+		}
+	}
+	for _, ptx := range affected {
+		if ptx.cancelFn != nil {
+			ptx.cancelFn()
+		}
+	}
+	p.mux.Unlock()
+
+	err := errors.Wrapf(ErrTimedOut, "%s: %v", dropped.txHash, dropErr)
+	resolveBatch(dropped.batch, &sdk.TxResponse{TxHash: dropped.txHash}, err)
+
+	// the sequence of the dropped Tx may have been used by another Tx of the account
+	seq := dropped.seq
+	var seqErr *ErrSequenceMismatch
+	if errors.As(dropErr, &seqErr) && seqErr.Expected > seq {
+		seq = seqErr.Expected
+	}
+
+	p.logger.WithFields(log.Fields{
+		"txHash":   dropped.txHash,
+		"sequence": seq,
+		"affected": len(affected),
+	}).Warningln("tx was not included, rolling back the sequence")
+
+	p.c.accSeq = seq
+	for _, ptx := range affected {
+		if ptx.seq < seq {
+			// the sequence has been used, possibly by the Tx itself
+			if res := p.lookup(ptx); res != nil {
+				resolveBatch(ptx.batch, res, nil)
+				continue
+			}
+		}
+
+		_ = p.submit(ptx.batch)
+	}
+}
+
+// lookup returns the Tx if it has been included.
+func (p *txPipeline) lookup(ptx *pendingTx) *sdk.TxResponse {
+	ctx, cancelFn := context.WithTimeout(context.Background(), p.c.opts.BroadcastTimeout)
+	defer cancelFn()
+
+	getRes, err := p.c.txClient.GetTx(ctx, &txtypes.GetTxRequest{Hash: ptx.txHash})
+	if err != nil {
+		if status.Code(err) != codes.NotFound {
+			p.logger.WithField("txHash", ptx.txHash).WithError(err).Warningln("failed to get tx")
+		}
+		return nil
+	}
+
+	return getRes.GetTxResponse()
+}
+
+func (p *txPipeline) isClosed() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.closed
+}
+
+// close stops the confirmation loop, resolving the pending Txs with ErrTxInFlight.
+// Batches submitted afterwards are resolved right away.
+func (p *txPipeline) close() {
+	p.mux.Lock()
+	if p.closed {
+		p.mux.Unlock()
+		return
+	}
+	p.closed = true
+	p.mux.Unlock()
+
+	close(p.quitC)
+	<-p.doneC
+}
@@ -0,0 +1,228 @@
+package chain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func awaitFutures(t *testing.T, futures []*MsgFuture) []MsgResult {
+	t.Helper()
+
+	results := make([]MsgResult, 0, len(futures))
+	for idx, future := range futures {
+		select {
+		case <-future.Done():
+			results = append(results, future.Result())
+		case <-time.After(5 * time.Second):
+			t.Fatalf("future %d was not resolved", idx)
+		}
+	}
+
+	return results
+}
+
+func TestPipelineAwaitsTxEvents(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	// inclusion can only be seen by its event within the broadcast timeout
+	c := newTestChainClient(t, signer, []*fakeNode{a},
+		common.OptionBroadcastStatusPoll(time.Second),
+		common.OptionBroadcastTimeout(5*time.Second),
+	)
+
+	events := newFakeEventsClient()
+	c.txSubs = newTxSubscriptions(events)
+	chain.onCommit = func(height int64, included []*fakeBroadcast) {
+		txs := make([][]byte, 0, len(included))
+		for _, broadcast := range included {
+			txs = append(txs, broadcast.txBytes)
+		}
+		go events.publish(height, txs...)
+	}
+
+	var futures []*MsgFuture
+	for i := 0; i < 3; i++ {
+		txFutures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, txFutures...)
+	}
+
+	start := time.Now()
+	chain.commit()
+
+	for idx, result := range awaitFutures(t, futures) {
+		if result.Err != nil {
+			t.Fatalf("tx %d: %v", idx, result.Err)
+		} else if result.Height == 0 {
+			t.Fatalf("tx %d: expected to be included", idx)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the tx events to be awaited instead of polling, took %s", elapsed)
+	} else if n := events.subscribeCount(); n != 1 {
+		t.Fatalf("expected the txs to share one subscription, got %d", n)
+	}
+}
+
+func TestPipelineKeepsWaitingForTxInMempool(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionBroadcastTimeout(100*time.Millisecond))
+
+	var futures []*MsgFuture
+	for i := 0; i < 2; i++ {
+		txFutures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, txFutures...)
+	}
+
+	// the txs are not included within the broadcast timeout, but still in the mempool
+	time.Sleep(350 * time.Millisecond)
+	chain.commit()
+
+	for idx, result := range awaitFutures(t, futures) {
+		if result.Err != nil {
+			t.Fatalf("tx %d: %v", idx, result.Err)
+		}
+	}
+
+	// only the same txs have been broadcast again, nothing has been re-signed
+	hashes := make(map[string]bool)
+	for _, broadcast := range chain.sentBroadcasts() {
+		hashes[broadcast.hash] = true
+	}
+	if len(hashes) != 2 {
+		t.Fatalf("expected the 2 txs to be rechecked without rollback, got %d different txs", len(hashes))
+	}
+}
+
+func TestPipelineRebroadcastsDroppedTx(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a}, common.OptionBroadcastTimeout(100*time.Millisecond))
+
+	var futures []*MsgFuture
+	for i := 0; i < 2; i++ {
+		txFutures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, txFutures...)
+	}
+
+	// the node restarted, the txs are still valid and accepted again by their recheck
+	chain.dropMempool()
+	time.Sleep(350 * time.Millisecond)
+	chain.commit()
+
+	for idx, result := range awaitFutures(t, futures) {
+		if result.Err != nil {
+			t.Fatalf("tx %d: %v", idx, result.Err)
+		}
+	}
+
+	if acc := chain.account(signer.Address()); acc.seq != 2 {
+		t.Fatalf("expected both txs to be included once, got sequence %d", acc.seq)
+	}
+}
+
+func TestPipelineRollsBackRejectedTx(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a},
+		common.OptionBroadcastTimeout(100*time.Millisecond),
+		common.OptionTimeoutHeight(2),
+	)
+
+	var futures []*MsgFuture
+	for i := 0; i < 2; i++ {
+		txFutures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, txFutures...)
+	}
+
+	// the txs were dropped and their timeout height has passed, so the first one is rejected
+	// by its recheck and the second one is re-signed
+	chain.dropMempool()
+	for i := 0; i < 5; i++ {
+		chain.commit()
+	}
+	chain.setAutoCommit(true)
+
+	results := awaitFutures(t, futures)
+	if err := results[0].Err; errors.Cause(err) != ErrTimedOut {
+		t.Fatalf("expected the dropped tx to time out, got %v", err)
+	} else if err := results[1].Err; err != nil {
+		t.Fatalf("expected the later tx to be re-signed and included, got %v", err)
+	}
+
+	if acc := chain.account(signer.Address()); acc.seq != 1 {
+		t.Fatalf("expected only the re-signed tx to be included, got sequence %d", acc.seq)
+	}
+}
+
+func TestPipelineCloseReportsTxsInFlight(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	futures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+
+	result, err := futures[0].Await(context.Background())
+	if errors.Cause(err) != ErrTxInFlight {
+		t.Fatalf("expected the pending tx to be reported in flight, got %v", err)
+	} else if result.TxHash == "" {
+		t.Fatal("expected the hash of the tx in flight")
+	}
+}
+
+func TestPipelineResolvesBatchesSubmittedAfterClose(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a})
+
+	// the client is being closed, the confirmation loop has already stopped
+	c.pipeline.close()
+
+	futures, err := c.PipelineBroadcastMsg(newTestMsgSend(signer))
+	if err != ErrQueueClosed {
+		t.Fatalf("expected the closed pipeline to reject the batch, got %v", err)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), time.Second)
+	defer cancelFn()
+	if _, err := futures[0].Await(ctx); err != ErrQueueClosed {
+		t.Fatalf("expected the future to resolve with the close error, got %v", err)
+	} else if len(chain.sentBroadcasts()) != 0 {
+		t.Fatal("expected nothing to be broadcast")
+	}
+}
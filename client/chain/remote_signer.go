@@ -0,0 +1,224 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+const (
+	remoteSignerPubKeyPath = "/pubkey"
+	remoteSignerSignPath   = "/sign"
+
+	defaultRemoteSignerTimeout = 30 * time.Second
+)
+
+// remotePubKey is the JSON encoding of the signer pubkey in the remote signer protocol.
+type remotePubKey struct {
+	Type string `json:"type"`
+	Key  []byte `json:"key"`
+}
+
+type remoteSignRequest struct {
+	SignBytes []byte `json:"sign_bytes"`
+	SignMode  string `json:"sign_mode"`
+}
+
+type remoteSignResponse struct {
+	Signature []byte `json:"signature"`
+}
+
+type remoteSignerError struct {
+	Error string `json:"error"`
+}
+
+type remoteSigner struct {
+	baseURL    string
+	httpClient *http.Client
+	pubKey     cryptotypes.PubKey
+}
+
+// NewRemoteSigner creates a Signer that requests signatures from a remote signer over HTTP,
+// e.g. one served by NewRemoteSignerHandler. The signer pubkey is fetched on creation.
+// A default client with a 30s timeout is used when httpClient is nil. Credentials the
+// remote signer requires are added by the transport of httpClient.
+func NewRemoteSigner(ctx context.Context, baseURL string, httpClient *http.Client) (Signer, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: defaultRemoteSignerTimeout,
+		}
+	}
+
+	s := &remoteSigner{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+
+	var res remotePubKey
+	if err := s.call(ctx, http.MethodGet, remoteSignerPubKeyPath, nil, &res); err != nil {
+		err = errors.Wrap(err, "failed to get remote signer pubkey")
+		return nil, err
+	}
+
+	pubKey, err := decodeRemotePubKey(res)
+	if err != nil {
+		return nil, err
+	}
+	s.pubKey = pubKey
+
+	return s, nil
+}
+
+func (s *remoteSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.pubKey.Address())
+}
+
+func (s *remoteSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s *remoteSigner) Sign(ctx context.Context, signBytes []byte) ([]byte, error) {
+	req := remoteSignRequest{
+		SignBytes: signBytes,
+		SignMode:  s.SignMode().String(),
+	}
+
+	var res remoteSignResponse
+	if err := s.call(ctx, http.MethodPost, remoteSignerSignPath, req, &res); err != nil {
+		err = errors.Wrap(err, "remote signer failed")
+		return nil, err
+	}
+
+	return res.Signature, nil
+}
+
+func (s *remoteSigner) SignMode() signingtypes.SignMode {
+	return signingtypes.SignMode_SIGN_MODE_DIRECT
+}
+
+func (s *remoteSigner) call(ctx context.Context, method, path string, req, res interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return err
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpRes.Body.Close()
+
+	if httpRes.StatusCode != http.StatusOK {
+		var errRes remoteSignerError
+		_ = json.NewDecoder(httpRes.Body).Decode(&errRes)
+		return errors.Errorf("%s: %s", httpRes.Status, errRes.Error)
+	}
+
+	return json.NewDecoder(httpRes.Body).Decode(res)
+}
+
+func decodeRemotePubKey(pubKey remotePubKey) (cryptotypes.PubKey, error) {
+	switch pubKey.Type {
+	case ethsecp256k1.KeyType:
+		return &ethsecp256k1.PubKey{Key: pubKey.Key}, nil
+	case new(secp256k1.PubKey).Type():
+		return &secp256k1.PubKey{Key: pubKey.Key}, nil
+	default:
+		return nil, errors.Errorf("unsupported remote signer pubkey type: %s", pubKey.Type)
+	}
+}
+
+// NewRemoteSignerHandler serves the signer over the HTTP protocol of NewRemoteSigner,
+// e.g. to run a signer service next to the keys, or a local stand-in for testing.
+//
+// WARNING: the handler signs whatever sign bytes it receives, so anyone who can reach it
+// can spend the funds of the signer account. Every request is passed to authorize first
+// and refused unless it returns nil, e.g. after checking a bearer token or the client
+// certificate. A nil authorize serves any caller, only use it on a trusted listener
+// such as a loopback address in tests.
+func NewRemoteSignerHandler(signer Signer, authorize func(r *http.Request) error) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(remoteSignerPubKeyPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeRemoteSignerError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		pubKey := signer.PubKey()
+		writeRemoteSignerJSON(w, remotePubKey{
+			Type: pubKey.Type(),
+			Key:  pubKey.Bytes(),
+		})
+	})
+
+	mux.HandleFunc(remoteSignerSignPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeRemoteSignerError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+
+		var req remoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeRemoteSignerError(w, http.StatusBadRequest, err)
+			return
+		} else if req.SignMode != signer.SignMode().String() {
+			err := errors.Errorf("unsupported sign mode %s", req.SignMode)
+			writeRemoteSignerError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		sigBytes, err := signer.Sign(r.Context(), req.SignBytes)
+		if err != nil {
+			writeRemoteSignerError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeRemoteSignerJSON(w, remoteSignResponse{
+			Signature: sigBytes,
+		})
+	})
+
+	if authorize == nil {
+		return mux
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authorize(r); err != nil {
+			writeRemoteSignerError(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func writeRemoteSignerJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeRemoteSignerError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(remoteSignerError{Error: err.Error()})
+}
@@ -0,0 +1,122 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/chain/crypto/ethsecp256k1"
+)
+
+const testRemoteSignerToken = "Bearer secret"
+
+// bearerTransport adds the credentials of the remote signer to every request.
+type bearerTransport struct {
+	token string
+}
+
+func (t bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", t.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func authorizeTestToken(r *http.Request) error {
+	if r.Header.Get("Authorization") != testRemoteSignerToken {
+		return errors.New("invalid token")
+	}
+
+	return nil
+}
+
+// blockingSigner doesn't return a signature before it is released or ctx is done.
+type blockingSigner struct {
+	Signer
+	releaseC chan struct{}
+}
+
+func (s blockingSigner) Sign(ctx context.Context, signBytes []byte) ([]byte, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.releaseC:
+		return nil, errors.New("released")
+	}
+}
+
+// startRemoteSigner serves the signer on a local stand-in for a remote signer.
+func startRemoteSigner(t *testing.T, signer Signer) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(NewRemoteSignerHandler(signer, authorizeTestToken))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestRemoteSignerSignsTx(t *testing.T) {
+	privKey, err := ethsecp256k1.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := NewPrivKeySigner(privKey)
+	server := startRemoteSigner(t, local)
+
+	httpClient := &http.Client{Transport: bearerTransport{token: testRemoteSignerToken}}
+	remote, err := NewRemoteSigner(context.Background(), server.URL, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	} else if !remote.Address().Equals(local.Address()) {
+		t.Fatalf("expected the remote signer address %s, got %s", local.Address(), remote.Address())
+	}
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed}, newTestMsgSend(local))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the signature is verified against the remote signer pubkey
+	if _, err := offlineTx.SignWithSigner(context.Background(), txConfig, remote); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemoteSignerRequiresAuthorization(t *testing.T) {
+	server := startRemoteSigner(t, newTestSigner())
+
+	if _, err := NewRemoteSigner(context.Background(), server.URL, nil); err == nil {
+		t.Fatal("expected the remote signer to refuse an unauthorized caller")
+	}
+
+	httpClient := &http.Client{Transport: bearerTransport{token: "Bearer wrong"}}
+	if _, err := NewRemoteSigner(context.Background(), server.URL, httpClient); err == nil {
+		t.Fatal("expected the remote signer to refuse a wrong token")
+	}
+}
+
+func TestRemoteSignerSignHonorsContext(t *testing.T) {
+	releaseC := make(chan struct{})
+	server := startRemoteSigner(t, blockingSigner{Signer: newTestSigner(), releaseC: releaseC})
+	t.Cleanup(func() { close(releaseC) })
+
+	httpClient := &http.Client{Transport: bearerTransport{token: testRemoteSignerToken}}
+	remote, err := NewRemoteSigner(context.Background(), server.URL, httpClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelFn()
+
+	start := time.Now()
+	if _, err := remote.Sign(ctx, []byte("sign bytes")); err == nil {
+		t.Fatal("expected the sign request to be canceled")
+	} else if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the sign request to stop at the ctx deadline, took %s", elapsed)
+	}
+}
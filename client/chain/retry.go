@@ -0,0 +1,68 @@
+package chain
+
+import (
+	"context"
+	"time"
+
+	log "github.com/InjectiveLabs/suplog"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// IsRetryableError is the default retry classification, transient failures of the node
+// and its full mempool are retried. Duplicate Txs are not, broadcasts resolve them by hash.
+func IsRetryableError(err error) bool {
+	switch status.Code(errors.Cause(err)) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	var mempoolErr *ErrMempoolFull
+	return errors.As(err, &mempoolErr) || errors.As(ParseGRPCError(err), &mempoolErr)
+}
+
+// RetryUnaryInterceptor retries failed unary calls with the backoff of the policy, until
+// they succeed, fail with an error the policy doesn't retry, or ctx is done.
+// Broadcasts rejected in CheckTx are classified by the typed error of their response code.
+func RetryUnaryInterceptor(policy common.RetryPolicy) grpc.UnaryClientInterceptor {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+
+	logger := log.WithFields(log.Fields{
+		"module": "sdk-go",
+		"svc":    "retryPolicy",
+	})
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+
+			failure := err
+			if res, ok := reply.(*txtypes.BroadcastTxResponse); ok && err == nil {
+				failure = ParseTxError(res.GetTxResponse())
+			}
+
+			if failure == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil || !retryable(failure) {
+				return err
+			}
+
+			backoff := policy.Backoff(attempt)
+			logger.WithError(failure).Debugf("retrying %s in %s, attempt %d of %d", method, backoff, attempt+1, policy.MaxAttempts)
+
+			t := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+		}
+	}
+}
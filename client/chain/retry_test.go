@@ -0,0 +1,130 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+func testRetryPolicy(maxAttempts int) common.RetryPolicy {
+	return common.RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+// failingInvoker fails the first calls with the errors, and succeeds after.
+func failingInvoker(calls *int, errs ...error) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls <= len(errs) {
+			return errs[*calls-1]
+		}
+
+		return nil
+	}
+}
+
+func TestIsRetryableError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err       error
+		retryable bool
+	}{
+		"unavailable":        {status.Error(codes.Unavailable, "connection refused"), true},
+		"deadline exceeded":  {status.Error(codes.DeadlineExceeded, "timeout"), true},
+		"resource exhausted": {status.Error(codes.ResourceExhausted, "too many txs"), true},
+		"mempool full":       {&ErrMempoolFull{}, true},
+		"invalid argument":   {status.Error(codes.InvalidArgument, "bad request"), false},
+		"tx in mempool":      {&ErrTxInMempool{}, false},
+		"sequence mismatch":  {&ErrSequenceMismatch{}, false},
+	} {
+		if retryable := IsRetryableError(tc.err); retryable != tc.retryable {
+			t.Errorf("%s: expected retryable %v, got %v", name, tc.retryable, retryable)
+		}
+	}
+}
+
+func TestRetryUnaryInterceptorRetriesTransientFailures(t *testing.T) {
+	interceptor := RetryUnaryInterceptor(testRetryPolicy(3))
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	var calls int
+	if err := interceptor(context.Background(), "/test", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable)); err != nil {
+		t.Fatalf("expected the third attempt to succeed, got %v", err)
+	} else if calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls)
+	}
+
+	calls = 0
+	err := interceptor(context.Background(), "/test", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable, unavailable))
+	if status.Code(err) != codes.Unavailable || calls != 3 {
+		t.Fatalf("expected to give up after 3 attempts, got %v after %d", err, calls)
+	}
+
+	calls = 0
+	invalid := status.Error(codes.InvalidArgument, "bad request")
+	if err := interceptor(context.Background(), "/test", nil, nil, nil, failingInvoker(&calls, invalid)); err != invalid || calls != 1 {
+		t.Fatalf("expected a non-retryable error not to be retried, got %v after %d", err, calls)
+	}
+}
+
+func TestRetryUnaryInterceptorRetriesFullMempoolResponses(t *testing.T) {
+	interceptor := RetryUnaryInterceptor(testRetryPolicy(3))
+
+	var calls int
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		res := reply.(*txtypes.BroadcastTxResponse)
+		res.TxResponse = &sdk.TxResponse{}
+		if calls == 1 {
+			res.TxResponse.Codespace = sdkerrors.RootCodespace
+			res.TxResponse.Code = sdkerrors.ErrMempoolIsFull.ABCICode()
+		}
+
+		return nil
+	}
+
+	reply := &txtypes.BroadcastTxResponse{}
+	if err := interceptor(context.Background(), "/test", nil, reply, nil, invoker); err != nil {
+		t.Fatal(err)
+	} else if calls != 2 || reply.TxResponse.Code != 0 {
+		t.Fatalf("expected the broadcast to be retried once, got %d calls with code %d", calls, reply.TxResponse.Code)
+	}
+}
+
+func TestRetryUnaryInterceptorHonorsContext(t *testing.T) {
+	policy := testRetryPolicy(10)
+	policy.InitialBackoff, policy.MaxBackoff = time.Hour, time.Hour
+	interceptor := RetryUnaryInterceptor(policy)
+
+	ctx, cancelFn := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelFn()
+
+	var calls int
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+	start := time.Now()
+	if err := interceptor(ctx, "/test", nil, nil, nil, failingInvoker(&calls, unavailable, unavailable)); !errors.Is(err, unavailable) {
+		t.Fatalf("expected the last error, got %v", err)
+	} else if calls != 1 || time.Since(start) > time.Second {
+		t.Fatalf("expected to stop waiting at the deadline, got %d calls after %s", calls, time.Since(start))
+	}
+
+	custom := testRetryPolicy(3)
+	custom.Retryable = func(err error) bool { return false }
+	calls = 0
+	if err := RetryUnaryInterceptor(custom)(context.Background(), "/test", nil, nil, nil, failingInvoker(&calls, unavailable)); err == nil || calls != 1 {
+		t.Fatalf("expected the custom classification to be used, got %v after %d", err, calls)
+	}
+}
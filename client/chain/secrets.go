@@ -0,0 +1,188 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+const defaultExecSecretTimeout = 30 * time.Second
+
+// SecretSource provides a secret, e.g. the keyring passphrase or a hex private key.
+// The caller owns the returned bytes and zeroes them after use. Zeroing is best effort only:
+// it doesn't reach copies made by the Go runtime, the keyring or the decoded keys, so it
+// shortens the lifetime of some copies of the secret rather than removing it from memory.
+type SecretSource interface {
+	Secret() ([]byte, error)
+}
+
+// SecretSourceFunc adapts a function to the SecretSource interface.
+type SecretSourceFunc func() ([]byte, error)
+
+func (f SecretSourceFunc) Secret() ([]byte, error) {
+	return f()
+}
+
+// PlainSecret provides a secret passed in plaintext, e.g. from a command line flag.
+// The secret stays in memory as an immutable string, which can't be zeroed.
+func PlainSecret(secret string) SecretSource {
+	return SecretSourceFunc(func() ([]byte, error) {
+		return []byte(secret), nil
+	})
+}
+
+// EnvSecret reads the secret from the environment variable, which must be set and non-empty.
+func EnvSecret(name string) SecretSource {
+	return SecretSourceFunc(func() ([]byte, error) {
+		secret, ok := os.LookupEnv(name)
+		if !ok || len(secret) == 0 {
+			return nil, errors.Errorf("environment variable %s is not set", name)
+		}
+
+		return []byte(secret), nil
+	})
+}
+
+// FileSecret reads the secret from the file, trimming the trailing newline. The file must not be
+// accessible by group or others, i.e. have 0600 or 0400 permissions, like SSH keys.
+func FileSecret(path string) SecretSource {
+	return SecretSourceFunc(func() ([]byte, error) {
+		stat, err := os.Stat(path)
+		if err != nil {
+			err = errors.Wrap(err, "failed to stat secret file")
+			return nil, err
+		} else if !stat.Mode().IsRegular() {
+			return nil, errors.Errorf("secret file %s is not a regular file", path)
+		} else if perm := stat.Mode().Perm(); perm&0077 != 0 {
+			return nil, errors.Errorf("secret file %s has permissions %04o, must not be accessible by group or others", path, perm)
+		}
+
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			err = errors.Wrap(err, "failed to read secret file")
+			return nil, err
+		}
+
+		return trimSecretNewline(secret), nil
+	})
+}
+
+// ExecSecret runs the credential helper command and reads the secret from its stdout,
+// trimming the trailing newline, e.g. ExecSecret("pass", "show", "gotabit/keyring").
+// The command must exit within 30s.
+func ExecSecret(name string, args ...string) SecretSource {
+	return SecretSourceFunc(func() ([]byte, error) {
+		ctx, cancelFn := context.WithTimeout(context.Background(), defaultExecSecretTimeout)
+		defer cancelFn()
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			zeroBytes(stdout.Bytes())
+			err = errors.Wrapf(err, "credential helper %s failed: %s", name, bytes.TrimSpace(stderr.Bytes()))
+			return nil, err
+		}
+
+		secret := make([]byte, stdout.Len())
+		copy(secret, stdout.Bytes())
+		zeroBytes(stdout.Bytes())
+
+		return trimSecretNewline(secret), nil
+	})
+}
+
+// TTYSecret prompts for the secret on the controlling terminal without echoing it.
+// It fails when the process has no terminal, e.g. when running as a service.
+func TTYSecret(prompt string) SecretSource {
+	return SecretSourceFunc(func() ([]byte, error) {
+		tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+		if err != nil {
+			err = errors.Wrap(err, "no terminal to prompt for the secret")
+			return nil, err
+		}
+		defer tty.Close()
+
+		fmt.Fprint(tty, prompt)
+		secret, err := term.ReadPassword(int(tty.Fd()))
+		fmt.Fprintln(tty)
+		if err != nil {
+			err = errors.Wrap(err, "failed to read secret from terminal")
+			return nil, err
+		}
+
+		return secret, nil
+	})
+}
+
+// readSecret reads the secret from the source and checks that it's not empty.
+func readSecret(source SecretSource, name string) ([]byte, error) {
+	secret, err := source.Secret()
+	if err != nil {
+		err = errors.Wrapf(err, "failed to read %s", name)
+		return nil, err
+	} else if len(secret) == 0 {
+		return nil, errors.Errorf("%s is empty", name)
+	}
+
+	return secret, nil
+}
+
+func trimSecretNewline(secret []byte) []byte {
+	trimmed := bytes.TrimRight(secret, "\r\n")
+	zeroBytes(secret[len(trimmed):])
+
+	return trimmed
+}
+
+// zeroBytes overwrites the bytes of the secret. Copies of it made elsewhere are not affected.
+func zeroBytes(bz []byte) {
+	for i := range bz {
+		bz[i] = 0
+	}
+}
+
+// secretReader feeds the secret to the keyring as a passphrase prompt answer. The secret is read
+// from the source whenever the keyring prompts, and its buffer is zeroed once consumed, although
+// the keyring keeps its own copy of the passphrase.
+type secretReader struct {
+	source  SecretSource
+	pending []byte
+}
+
+var _ io.Reader = &secretReader{}
+
+func newSecretReader(source SecretSource) io.Reader {
+	return &secretReader{
+		source: source,
+	}
+}
+
+func (r *secretReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		secret, err := readSecret(r.source, "keyring passphrase")
+		if err != nil {
+			return 0, err
+		}
+
+		r.pending = make([]byte, len(secret)+1)
+		copy(r.pending, secret)
+		r.pending[len(secret)] = '\n'
+		zeroBytes(secret)
+	}
+
+	n := copy(p, r.pending)
+	zeroBytes(r.pending[:n])
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
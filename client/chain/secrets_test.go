@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestEnvSecret(t *testing.T) {
+	t.Setenv("TEST_CHAIN_SECRET", "passphrase")
+
+	secret, err := EnvSecret("TEST_CHAIN_SECRET").Secret()
+	if err != nil {
+		t.Fatal(err)
+	} else if string(secret) != "passphrase" {
+		t.Fatalf("unexpected secret %q", secret)
+	}
+
+	t.Setenv("TEST_CHAIN_SECRET", "")
+	if _, err := EnvSecret("TEST_CHAIN_SECRET").Secret(); err == nil {
+		t.Fatal("expected an empty environment variable to be rejected")
+	}
+}
+
+func TestFileSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("passphrase\r\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	secret, err := FileSecret(path).Secret()
+	if err != nil {
+		t.Fatal(err)
+	} else if string(secret) != "passphrase" {
+		t.Fatalf("expected the trailing newline to be trimmed, got %q", secret)
+	}
+
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	} else if _, err := FileSecret(path).Secret(); err == nil {
+		t.Fatal("expected a file readable by others to be rejected")
+	}
+
+	if _, err := FileSecret(t.TempDir()).Secret(); err == nil {
+		t.Fatal("expected a directory to be rejected")
+	}
+}
+
+func TestExecSecret(t *testing.T) {
+	secret, err := ExecSecret("sh", "-c", "echo passphrase").Secret()
+	if err != nil {
+		t.Fatal(err)
+	} else if string(secret) != "passphrase" {
+		t.Fatalf("unexpected secret %q", secret)
+	}
+
+	if _, err := ExecSecret("sh", "-c", "echo denied >&2; exit 1").Secret(); err == nil {
+		t.Fatal("expected a failed credential helper to be rejected")
+	}
+}
+
+func TestSecretReaderAnswersEachPrompt(t *testing.T) {
+	var reads int
+	reader := newSecretReader(SecretSourceFunc(func() ([]byte, error) {
+		reads++
+		return []byte("passphrase"), nil
+	}))
+
+	// the keyring reads the prompt answers byte by byte
+	buf := make([]byte, 4)
+	var answers string
+	for len(answers) < 2*len("passphrase\n") {
+		n, err := reader.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		answers += string(buf[:n])
+	}
+
+	if answers != "passphrase\npassphrase\n" {
+		t.Fatalf("unexpected answers %q", answers)
+	} else if reads != 2 {
+		t.Fatalf("expected the secret to be read once per prompt, got %d reads", reads)
+	}
+}
+
+func TestSecretReaderFailsOnSourceError(t *testing.T) {
+	reader := newSecretReader(SecretSourceFunc(func() ([]byte, error) {
+		return nil, errors.New("no secret")
+	}))
+
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Fatal("expected the source error to be returned")
+	}
+
+	reader = newSecretReader(PlainSecret(""))
+	if _, err := reader.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected an empty secret to be rejected")
+	}
+}
+
+func TestTrimSecretNewlineZeroesTrimmedBytes(t *testing.T) {
+	secret := []byte("passphrase\n")
+	trimmed := trimSecretNewline(secret)
+
+	if string(trimmed) != "passphrase" {
+		t.Fatalf("unexpected secret %q", trimmed)
+	} else if secret[len(secret)-1] != 0 {
+		t.Fatal("expected the trimmed newline to be zeroed")
+	}
+}
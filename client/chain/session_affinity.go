@@ -0,0 +1,222 @@
+package chain
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/InjectiveLabs/suplog"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// sessionAffinity keeps the session cookies a load balancer sets on the responses of an endpoint,
+// and sends them with the following calls so they are served by the same node behind it.
+// Cookies are persisted under dir, one file per endpoint, or only kept in memory when dir is empty.
+type sessionAffinity struct {
+	endpoint string
+	secure   bool
+	dir      string
+	logger   log.Logger
+	now      func() time.Time
+
+	mux     sync.Mutex
+	cookies []*http.Cookie
+}
+
+func newSessionAffinity(endpoint string, secure bool, dir string) *sessionAffinity {
+	s := &sessionAffinity{
+		endpoint: endpoint,
+		secure:   secure,
+		dir:      dir,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "sessionAffinity",
+		}),
+		now: time.Now,
+	}
+
+	if err := s.load(); err != nil {
+		s.logger.WithError(err).Warningln("failed to load session cookies of", endpoint)
+	}
+
+	return s
+}
+
+// unaryInterceptor sends the session cookies with every call and keeps the cookies set by its response.
+func (s *sessionAffinity) unaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var header metadata.MD
+		err := invoker(s.outgoingContext(ctx), method, req, reply, cc, append(opts, grpc.Header(&header))...)
+		s.observe(header)
+
+		return err
+	}
+}
+
+// streamInterceptor sends the session cookies when streams are opened.
+func (s *sessionAffinity) streamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(s.outgoingContext(ctx), desc, cc, method, opts...)
+	}
+}
+
+// outgoingContext appends the cookie header of the unexpired session cookies to the call metadata.
+func (s *sessionAffinity) outgoingContext(ctx context.Context) context.Context {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+	pairs := make([]string, 0, len(s.cookies))
+	for _, cookie := range s.cookies {
+		if cookie.Secure && !s.secure {
+			continue
+		} else if !cookie.Expires.IsZero() && !cookie.Expires.After(now) {
+			continue
+		}
+
+		pairs = append(pairs, (&http.Cookie{Name: cookie.Name, Value: cookie.Value}).String())
+	}
+
+	if len(pairs) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "cookie", strings.Join(pairs, "; "))
+}
+
+// observe merges the cookies of the set-cookie response headers into the session cookies.
+// Malformed cookies are skipped, and unknown or invalid attributes ignored.
+func (s *sessionAffinity) observe(header metadata.MD) {
+	setCookies := header.Get("set-cookie")
+	if len(setCookies) == 0 {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	now := s.now()
+	before := encodeCookies(s.cookies)
+	for _, cookie := range parseSetCookies(setCookies) {
+		// relative expiry is stored as absolute time, so it survives restarts
+		if cookie.MaxAge > 0 {
+			cookie.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second)
+			cookie.MaxAge = 0
+		}
+
+		expired := cookie.MaxAge < 0 || (!cookie.Expires.IsZero() && !cookie.Expires.After(now))
+		s.cookies = mergeCookie(s.cookies, cookie, expired)
+	}
+
+	// load balancers set the same cookies on every response, don't rewrite the file for them
+	if bytes.Equal(before, encodeCookies(s.cookies)) {
+		return
+	}
+
+	if err := s.save(); err != nil {
+		s.logger.WithError(err).Warningln("failed to save session cookies of", s.endpoint)
+	}
+}
+
+func (s *sessionAffinity) filename() string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s.endpoint)
+
+	return filepath.Join(s.dir, name+".cookies")
+}
+
+// load reads the cookies saved by a previous client of the endpoint, a missing file is not an error.
+func (s *sessionAffinity) load() error {
+	if len(s.dir) == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.filename())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var setCookies []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); len(line) > 0 {
+			setCookies = append(setCookies, line)
+		}
+	}
+
+	s.mux.Lock()
+	s.cookies = parseSetCookies(setCookies)
+	s.mux.Unlock()
+
+	return nil
+}
+
+// save writes the cookies, must be called with mux held.
+func (s *sessionAffinity) save() error {
+	if len(s.dir) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		err = errors.Wrap(err, "failed to create session dir")
+		return err
+	}
+
+	return os.WriteFile(s.filename(), encodeCookies(s.cookies), 0600)
+}
+
+// encodeCookies encodes the cookies as set-cookie lines.
+func encodeCookies(cookies []*http.Cookie) []byte {
+	buf := new(bytes.Buffer)
+	for _, cookie := range cookies {
+		buf.WriteString(cookie.String())
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+// parseSetCookies parses set-cookie header values with net/http.
+func parseSetCookies(setCookies []string) []*http.Cookie {
+	res := &http.Response{
+		Header: http.Header{
+			"Set-Cookie": setCookies,
+		},
+	}
+
+	return res.Cookies()
+}
+
+// mergeCookie replaces the cookie of the same name, path and domain in place, or removes it when expired.
+func mergeCookie(cookies []*http.Cookie, cookie *http.Cookie, expired bool) []*http.Cookie {
+	merged := make([]*http.Cookie, 0, len(cookies)+1)
+	replaced := false
+	for _, c := range cookies {
+		if c.Name != cookie.Name || c.Path != cookie.Path || c.Domain != cookie.Domain {
+			merged = append(merged, c)
+		} else if !expired && !replaced {
+			merged = append(merged, cookie)
+			replaced = true
+		}
+	}
+
+	if !expired && !replaced {
+		merged = append(merged, cookie)
+	}
+
+	return merged
+}
@@ -0,0 +1,198 @@
+package chain
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeLoadBalancer is a gRPC server that sets a session cookie on its responses,
+// like a load balancer with sticky sessions in front of the nodes.
+type fakeLoadBalancer struct {
+	banktypes.UnimplementedQueryServer
+
+	mux       sync.Mutex
+	setCookie string
+	cookies   []string
+}
+
+func (lb *fakeLoadBalancer) Balance(ctx context.Context, req *banktypes.QueryBalanceRequest) (*banktypes.QueryBalanceResponse, error) {
+	lb.mux.Lock()
+	defer lb.mux.Unlock()
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	lb.cookies = append(lb.cookies, strings.Join(md.Get("cookie"), "; "))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("set-cookie", lb.setCookie))
+
+	coin := sdk.NewInt64Coin("aaa", 1)
+	return &banktypes.QueryBalanceResponse{Balance: &coin}, nil
+}
+
+func (lb *fakeLoadBalancer) setSessionCookie(setCookie string) {
+	lb.mux.Lock()
+	lb.setCookie = setCookie
+	lb.mux.Unlock()
+}
+
+// lastCookie returns the cookie header of the last call.
+func (lb *fakeLoadBalancer) lastCookie() string {
+	lb.mux.Lock()
+	defer lb.mux.Unlock()
+
+	return lb.cookies[len(lb.cookies)-1]
+}
+
+func startFakeLoadBalancer(t *testing.T, setCookie string) (*fakeLoadBalancer, string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lb := &fakeLoadBalancer{setCookie: setCookie}
+	server := grpc.NewServer()
+	banktypes.RegisterQueryServer(server, lb)
+
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return lb, lis.Addr().String()
+}
+
+// dialWithSession connects to the address with the interceptors of the session.
+func dialWithSession(t *testing.T, addr string, session *sessionAffinity) banktypes.QueryClient {
+	t.Helper()
+
+	conn, err := grpc.Dial(addr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(session.unaryInterceptor()),
+		grpc.WithStreamInterceptor(session.streamInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return banktypes.NewQueryClient(conn)
+}
+
+// fakeClock is the time of a session, moved forward by tests.
+type fakeClock struct {
+	mux sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mux.Lock()
+	c.now = c.now.Add(d)
+	c.mux.Unlock()
+}
+
+func newTestSession(addr, dir string, clock *fakeClock) *sessionAffinity {
+	session := newSessionAffinity(addr, false, dir)
+	session.now = clock.Now
+
+	return session
+}
+
+func queryBalance(t *testing.T, client banktypes.QueryClient) {
+	t.Helper()
+
+	if _, err := client.Balance(context.Background(), &banktypes.QueryBalanceRequest{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionAffinitySendsCookieUntilExpired(t *testing.T) {
+	lb, addr := startFakeLoadBalancer(t, "lb=node-1; Max-Age=60")
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	client := dialWithSession(t, addr, newTestSession(addr, "", clock))
+
+	queryBalance(t, client)
+	if cookie := lb.lastCookie(); cookie != "" {
+		t.Fatalf("expected no cookie before the first response, got %q", cookie)
+	}
+
+	queryBalance(t, client)
+	if cookie := lb.lastCookie(); cookie != "lb=node-1" {
+		t.Fatalf("expected the session cookie, got %q", cookie)
+	}
+
+	// the load balancer stops refreshing the cookie, it expires a minute after the last response
+	lb.setSessionCookie("other=1")
+	clock.advance(61 * time.Second)
+	queryBalance(t, client)
+	if cookie := lb.lastCookie(); cookie != "" {
+		t.Fatalf("expected the expired cookie not to be sent, got %q", cookie)
+	}
+}
+
+func TestSessionAffinityRestoresSavedCookies(t *testing.T) {
+	lb, addr := startFakeLoadBalancer(t, "lb=node-1; Max-Age=60")
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	dir := t.TempDir()
+
+	queryBalance(t, dialWithSession(t, addr, newTestSession(addr, dir, clock)))
+
+	// a new client of the endpoint keeps the session of the previous one
+	clock.advance(30 * time.Second)
+	queryBalance(t, dialWithSession(t, addr, newTestSession(addr, dir, clock)))
+	if cookie := lb.lastCookie(); cookie != "lb=node-1" {
+		t.Fatalf("expected the saved session cookie, got %q", cookie)
+	}
+
+	clock.advance(time.Minute)
+	queryBalance(t, dialWithSession(t, addr, newTestSession(addr, dir, clock)))
+	if cookie := lb.lastCookie(); cookie != "" {
+		t.Fatalf("expected the saved cookie to expire, got %q", cookie)
+	}
+}
+
+func TestSessionAffinitySavesOnlyChangedCookies(t *testing.T) {
+	lb, addr := startFakeLoadBalancer(t, "lb=node-1; Max-Age=60")
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	dir := t.TempDir()
+
+	session := newTestSession(addr, dir, clock)
+	client := dialWithSession(t, addr, session)
+
+	queryBalance(t, client)
+	if _, err := os.Stat(session.filename()); err != nil {
+		t.Fatalf("expected the new session cookie to be saved: %v", err)
+	}
+
+	// the same cookie set again must not rewrite the file
+	if err := os.Remove(session.filename()); err != nil {
+		t.Fatal(err)
+	}
+	queryBalance(t, client)
+	if _, err := os.Stat(session.filename()); !os.IsNotExist(err) {
+		t.Fatalf("expected the unchanged cookies not to be saved again, got %v", err)
+	}
+
+	lb.setSessionCookie("lb=node-2; Max-Age=60")
+	queryBalance(t, client)
+	data, err := os.ReadFile(session.filename())
+	if err != nil {
+		t.Fatalf("expected the changed session cookie to be saved: %v", err)
+	} else if !strings.Contains(string(data), "lb=node-2") {
+		t.Fatalf("expected the saved cookie to be replaced, got %q", data)
+	}
+}
@@ -0,0 +1,180 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	signingtypes "github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/chain/crypto/hd"
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// Signer signs Txs on behalf of a single account, independently of where its key is stored.
+type Signer interface {
+	Address() sdk.AccAddress
+	PubKey() cryptotypes.PubKey
+	// Sign returns the signature of the sign bytes, which are computed for SignMode.
+	// Signers that call out, e.g. to a remote service, give up once ctx is done.
+	Sign(ctx context.Context, signBytes []byte) ([]byte, error)
+	SignMode() signingtypes.SignMode
+}
+
+type privKeySigner struct {
+	privKey cryptotypes.PrivKey
+}
+
+// NewPrivKeySigner creates a Signer for a raw private key, e.g. an ethsecp256k1.PrivKey.
+func NewPrivKeySigner(privKey cryptotypes.PrivKey) Signer {
+	return &privKeySigner{
+		privKey: privKey,
+	}
+}
+
+func (s *privKeySigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.privKey.PubKey().Address())
+}
+
+func (s *privKeySigner) PubKey() cryptotypes.PubKey {
+	return s.privKey.PubKey()
+}
+
+func (s *privKeySigner) Sign(_ context.Context, signBytes []byte) ([]byte, error) {
+	return s.privKey.Sign(signBytes)
+}
+
+func (s *privKeySigner) SignMode() signingtypes.SignMode {
+	return signingtypes.SignMode_SIGN_MODE_DIRECT
+}
+
+type keyringSigner struct {
+	kb   keyring.Keyring
+	info keyring.Info
+}
+
+// NewKeyringSigner creates a Signer for the named key of the keyring. Ledger keys
+// sign in amino JSON mode, since the device can't display protobuf Txs.
+func NewKeyringSigner(kb keyring.Keyring, keyName string) (Signer, error) {
+	info, err := kb.Key(keyName)
+	if err != nil {
+		err = errors.Wrapf(err, "no key in keyring for name: %s", keyName)
+		return nil, err
+	}
+
+	switch info.GetType() {
+	case keyring.TypeOffline, keyring.TypeMulti:
+		err := errors.Errorf("'%s' key is a watch-only %s key and can't sign", keyName, info.GetType())
+		return nil, err
+	}
+
+	return &keyringSigner{
+		kb:   kb,
+		info: info,
+	}, nil
+}
+
+func (s *keyringSigner) Address() sdk.AccAddress {
+	return s.info.GetAddress()
+}
+
+func (s *keyringSigner) PubKey() cryptotypes.PubKey {
+	return s.info.GetPubKey()
+}
+
+func (s *keyringSigner) Sign(_ context.Context, signBytes []byte) ([]byte, error) {
+	sigBytes, _, err := s.kb.Sign(s.info.GetName(), signBytes)
+	return sigBytes, err
+}
+
+func (s *keyringSigner) SignMode() signingtypes.SignMode {
+	if s.info.GetType() == keyring.TypeLedger {
+		return signingtypes.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+	}
+
+	return signingtypes.SignMode_SIGN_MODE_DIRECT
+}
+
+// NewMnemonicSigner creates a Signer for the key of the signing algorithm derived from the mnemonic
+// at the HD path, e.g. hd.EthSecp256k1 at "m/44'/60'/0'/0/0". The derived key is only kept in memory.
+func NewMnemonicSigner(algo keyring.SignatureAlgo, mnemonic, bip39Passphrase, hdPath string) (Signer, error) {
+	privKey, err := hd.DerivePrivKey(algo, mnemonic, bip39Passphrase, hdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrivKeySigner(privKey), nil
+}
+
+// externalSigner adapts an ExternalSigner of a watch-only key to the Signer interface.
+type externalSigner struct {
+	pubKey cryptotypes.PubKey
+	signer ExternalSigner
+}
+
+func (e *externalSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(e.pubKey.Address())
+}
+
+func (e *externalSigner) PubKey() cryptotypes.PubKey {
+	return e.pubKey
+}
+
+func (e *externalSigner) Sign(ctx context.Context, signBytes []byte) ([]byte, error) {
+	sigBytes, err := e.signer.Sign(ctx, e.pubKey, signBytes)
+	if err != nil {
+		err = errors.Wrap(err, "external signer failed")
+		return nil, err
+	}
+
+	return sigBytes, nil
+}
+
+func (e *externalSigner) SignMode() signingtypes.SignMode {
+	return signingtypes.SignMode_SIGN_MODE_DIRECT
+}
+
+// signTx signs the Tx with the signer, replacing any existing signatures.
+// The returned signature is verified against the signer pubkey, as it may come from a remote party.
+// Errors refer to the signer by its address with the prefix of chainConfig.
+func signTx(ctx context.Context, chainConfig common.ChainConfig, txConfig client.TxConfig, txn client.TxBuilder, signerData authsigning.SignerData, signer Signer) error {
+	pubKey := signer.PubKey()
+	sigData := &signingtypes.SingleSignatureData{
+		SignMode: signer.SignMode(),
+	}
+	sig := signingtypes.SignatureV2{
+		PubKey:   pubKey,
+		Data:     sigData,
+		Sequence: signerData.Sequence,
+	}
+
+	// signer infos are part of the sign bytes, so they must be set before signing
+	if err := txn.SetSignatures(sig); err != nil {
+		err = errors.Wrap(err, "failed to set signer info")
+		return err
+	}
+
+	signBytes, err := txConfig.SignModeHandler().GetSignBytes(sigData.SignMode, signerData, txn.GetTx())
+	if err != nil {
+		err = errors.Wrap(err, "failed to get sign bytes")
+		return err
+	}
+
+	if sigData.Signature, err = signer.Sign(ctx, signBytes); err != nil {
+		return err
+	} else if !pubKey.VerifySignature(signBytes, sigData.Signature) {
+		err = errors.Errorf("signer returned an invalid signature for %s", chainConfig.FormatAccAddress(signer.Address()))
+		return err
+	}
+
+	if err := txn.SetSignatures(sig); err != nil {
+		err = errors.Wrap(err, "failed to set signature")
+		return err
+	}
+
+	return nil
+}
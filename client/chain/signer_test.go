@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	cosmoshd "github.com/cosmos/cosmos-sdk/crypto/hd"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+
+	"github.com/gotabit/sdk-go/chain/crypto/hd"
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// fakeExternalSigner signs with a private key, recording the ctx it was called with.
+type fakeExternalSigner struct {
+	privKey cryptotypes.PrivKey
+	ctx     context.Context
+}
+
+func (s *fakeExternalSigner) Sign(ctx context.Context, pubKey cryptotypes.PubKey, signBytes []byte) ([]byte, error) {
+	s.ctx = ctx
+	return s.privKey.Sign(signBytes)
+}
+
+type ctxKey struct{}
+
+func TestSignWithExternalSignerPassesContext(t *testing.T) {
+	signer := newTestSigner().(*privKeySigner)
+	external := &fakeExternalSigner{privKey: signer.privKey}
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed}, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "caller")
+	if _, err := offlineTx.SignWithExternalSigner(ctx, txConfig, signer.PubKey(), external); err != nil {
+		t.Fatal(err)
+	} else if external.ctx == nil || external.ctx.Value(ctxKey{}) != "caller" {
+		t.Fatal("expected the external signer to be called with the caller ctx")
+	}
+}
+
+func TestSignTxRejectsSignatureOfAnotherKey(t *testing.T) {
+	signer := newTestSigner().(*privKeySigner)
+	external := &fakeExternalSigner{privKey: newTestSigner().(*privKeySigner).privKey}
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed}, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := offlineTx.SignWithExternalSigner(context.Background(), txConfig, signer.PubKey(), external); err == nil {
+		t.Fatal("expected the signature of another key to be rejected")
+	}
+}
+
+func TestSignTxErrorUsesChainPrefix(t *testing.T) {
+	signer := newTestSigner().(*privKeySigner)
+	external := &externalSigner{
+		pubKey: signer.PubKey(),
+		signer: &fakeExternalSigner{privKey: newTestSigner().(*privKeySigner).privKey},
+	}
+
+	txConfig := newFakeChain().txConfig
+	offlineTx, err := NewOfflineTx(txConfig, OfflineTxParams{ChainID: "test-1", Gas: fakeGasUsed}, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn, err := offlineTx.TxBuilder(txConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chainConfig := common.GotabitChainConfig()
+	err = signTx(context.Background(), chainConfig, txConfig, txn, authsigning.SignerData{ChainID: "test-1"}, external)
+	if err == nil {
+		t.Fatal("expected the signature of another key to be rejected")
+	} else if addr := chainConfig.FormatAccAddress(signer.Address()); !strings.Contains(err.Error(), addr) {
+		t.Fatalf("expected the error to refer to %s, got %v", addr, err)
+	}
+}
+
+func TestNewMnemonicSignerDerivesKeyOfAlgo(t *testing.T) {
+	ethSigner, err := NewMnemonicSigner(hd.EthSecp256k1, testMnemonic, "", "m/44'/60'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cosmosSigner, err := NewMnemonicSigner(cosmoshd.Secp256k1, testMnemonic, "", "m/44'/118'/0'/0/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if keyType := ethSigner.PubKey().Type(); keyType != "eth_secp256k1" {
+		t.Fatalf("expected an eth_secp256k1 key, got %s", keyType)
+	} else if keyType := cosmosSigner.PubKey().Type(); keyType != "secp256k1" {
+		t.Fatalf("expected a secp256k1 key, got %s", keyType)
+	}
+
+	// the well-known address of the mnemonic on cosmos hub
+	if addr, _ := sdk.Bech32ifyAddressBytes("cosmos", cosmosSigner.Address()); addr != "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4" {
+		t.Fatalf("unexpected address %s", addr)
+	}
+}
+
+func TestNewMnemonicSignerRejectsInvalidMnemonic(t *testing.T) {
+	if _, err := NewMnemonicSigner(hd.EthSecp256k1, "abandon abandon", "", "m/44'/60'/0'/0/0"); err == nil {
+		t.Fatal("expected an invalid mnemonic to be rejected")
+	}
+}
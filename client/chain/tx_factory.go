@@ -3,7 +3,11 @@ package chain
 import (
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/pkg/errors"
+
+	"github.com/gotabit/sdk-go/client/common"
 )
 
 func NewTxFactory(clientCtx client.Context) tx.Factory {
@@ -12,7 +16,61 @@ func NewTxFactory(clientCtx client.Context) tx.Factory {
 		WithTxConfig(clientCtx.TxConfig).
 		WithAccountRetriever(clientCtx.AccountRetriever).
 		WithSimulateAndExecute(true).
-		WithGasAdjustment(1.5).
+		WithGasAdjustment(common.DefaultGasAdjustment).
 		WithChainID(clientCtx.ChainID).
 		WithSignMode(signing.SignMode_SIGN_MODE_DIRECT)
 }
+
+// TxOverrides overrides the Tx parameters of a single broadcast, zero values keep the client defaults.
+type TxOverrides struct {
+	// Gas disables simulation and uses the provided gas limit
+	Gas uint64
+	// Fees are the exact Tx fees, they take precedence over GasPrices
+	Fees string
+	// GasPrices are used to compute the Tx fees from the gas limit
+	GasPrices     string
+	Memo          string
+	TimeoutHeight uint64
+	FeeGranter    sdk.AccAddress
+	// AccountNumber and Sequence are only used by BuildUnsignedTx, broadcasts use the
+	// sequence tracked by the client. Offline clients use them as is, zero included.
+	AccountNumber uint64
+	Sequence      uint64
+}
+
+func (o TxOverrides) apply(clientCtx client.Context, txf tx.Factory) (client.Context, tx.Factory, error) {
+	if o.Gas > 0 {
+		clientCtx = clientCtx.WithSimulation(false)
+		txf = txf.WithGas(o.Gas)
+	}
+
+	if len(o.Fees) > 0 {
+		if _, err := sdk.ParseCoinsNormalized(o.Fees); err != nil {
+			err = errors.Wrapf(err, "failed to ParseCoins %s", o.Fees)
+			return clientCtx, txf, err
+		}
+
+		txf = txf.WithGasPrices("").WithFees(o.Fees)
+	} else if len(o.GasPrices) > 0 {
+		if _, err := sdk.ParseDecCoins(o.GasPrices); err != nil {
+			err = errors.Wrapf(err, "failed to ParseDecCoins %s", o.GasPrices)
+			return clientCtx, txf, err
+		}
+
+		txf = txf.WithGasPrices(o.GasPrices)
+	}
+
+	if len(o.Memo) > 0 {
+		txf = txf.WithMemo(o.Memo)
+	}
+
+	if o.TimeoutHeight > 0 {
+		txf = txf.WithTimeoutHeight(o.TimeoutHeight)
+	}
+
+	if o.FeeGranter != nil {
+		clientCtx = clientCtx.WithFeeGranterAddress(o.FeeGranter)
+	}
+
+	return clientCtx, txf, nil
+}
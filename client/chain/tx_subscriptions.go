@@ -0,0 +1,209 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	log "github.com/InjectiveLabs/suplog"
+	"github.com/pkg/errors"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+const (
+	// txEventsCapacity buffers the Tx events of a few blocks, events that don't fit are dropped
+	// by the websocket client and only picked up by polling.
+	txEventsCapacity = 1000
+	// txEventsPollFactor slows down polling for Txs awaiting their event, since it only
+	// catches the events that were missed.
+	txEventsPollFactor = 10
+)
+
+// txEventsClient is the part of the Tendermint RPC client Tx subscriptions use.
+type txEventsClient interface {
+	IsRunning() bool
+	Start() error
+	Stop() error
+	Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan ctypes.ResultEvent, error)
+	UnsubscribeAll(ctx context.Context, subscriber string) error
+}
+
+// txSubscriptions shares a single subscription to all Tx events of the Tendermint websocket
+// between the Txs of the client awaiting inclusion, dispatching the events by Tx hash.
+// Tendermint limits the subscriptions of a websocket client (max_subscriptions_per_client,
+// 5 by default), so Txs don't subscribe to their own tx.hash query. The trade-off is that
+// the events of every Tx of the chain are received: on busy chains events beyond the
+// txEventsCapacity buffer are dropped by the websocket client, and the node cancels the
+// subscription of clients that fall behind. Txs whose event is lost are found by awaitTx
+// polling, at the slower pace of txEventsPollFactor.
+type txSubscriptions struct {
+	client     txEventsClient
+	subscriber string
+	logger     log.Logger
+
+	mux     sync.Mutex
+	started bool
+	closed  bool
+	eventC  <-chan ctypes.ResultEvent
+	quitC   chan struct{}
+	waiters map[string][]chan *ctypes.ResultTx
+}
+
+func newTxSubscriptions(client txEventsClient) *txSubscriptions {
+	s := &txSubscriptions{
+		client: client,
+		logger: log.WithFields(log.Fields{
+			"module": "sdk-go",
+			"svc":    "txSubscriptions",
+		}),
+		quitC:   make(chan struct{}),
+		waiters: make(map[string][]chan *ctypes.ResultTx),
+	}
+	s.subscriber = fmt.Sprintf("sdk-go-%p", s)
+
+	return s
+}
+
+// ensureSubscribed starts the websocket and subscribes to all Tx events, unless already subscribed.
+func (s *txSubscriptions) ensureSubscribed(ctx context.Context) error {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return errors.New("tx subscriptions are closed")
+	} else if s.client == nil {
+		return errors.New("no tendermint client to subscribe with")
+	} else if s.eventC != nil {
+		return nil
+	}
+
+	if !s.client.IsRunning() {
+		if err := s.client.Start(); err != nil {
+			err = errors.Wrap(err, "failed to start tendermint websocket")
+			return err
+		}
+		s.started = true
+	}
+
+	query := tmtypes.EventQueryTx.String()
+	eventC, err := s.client.Subscribe(ctx, s.subscriber, query, txEventsCapacity)
+	if err != nil {
+		err = errors.Wrapf(err, "failed to subscribe to %s", query)
+		return err
+	}
+
+	s.eventC = eventC
+	go s.dispatch(eventC)
+
+	return nil
+}
+
+// subscribe registers for the inclusion event of the Tx with the hash. The returned unsubscribe
+// func must be called once the Tx isn't awaited anymore. Events can be missed, e.g. while the
+// websocket reconnects, so the Tx inclusion must be polled for as well.
+func (s *txSubscriptions) subscribe(ctx context.Context, txHash string) (<-chan *ctypes.ResultTx, func(), error) {
+	if err := s.ensureSubscribed(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	txHash = strings.ToUpper(txHash)
+	resultC := make(chan *ctypes.ResultTx, 1)
+
+	s.mux.Lock()
+	s.waiters[txHash] = append(s.waiters[txHash], resultC)
+	s.mux.Unlock()
+
+	unsubscribe := func() {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+
+		waiters := s.waiters[txHash]
+		for idx, waiter := range waiters {
+			if waiter == resultC {
+				waiters = append(waiters[:idx], waiters[idx+1:]...)
+				break
+			}
+		}
+
+		if len(waiters) == 0 {
+			delete(s.waiters, txHash)
+		} else {
+			s.waiters[txHash] = waiters
+		}
+	}
+
+	return resultC, unsubscribe, nil
+}
+
+// dispatch delivers the Tx events to the Txs awaiting them, until the subscriptions are closed.
+func (s *txSubscriptions) dispatch(eventC <-chan ctypes.ResultEvent) {
+	for {
+		select {
+		case <-s.quitC:
+			return
+		case event, ok := <-eventC:
+			if !ok {
+				// subscribe again on the next awaited Tx
+				s.mux.Lock()
+				s.eventC = nil
+				s.mux.Unlock()
+
+				s.logger.Warningln("tx events subscription has been closed")
+				return
+			}
+
+			resultTx, ok := resultTxFromEvent(event)
+			if !ok {
+				continue
+			}
+
+			txHash := fmt.Sprintf("%X", resultTx.Hash)
+
+			s.mux.Lock()
+			for _, waiter := range s.waiters[txHash] {
+				select {
+				case waiter <- resultTx:
+				default:
+				}
+			}
+			s.mux.Unlock()
+		}
+	}
+}
+
+func (s *txSubscriptions) close() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.quitC)
+
+	if s.eventC != nil {
+		_ = s.client.UnsubscribeAll(context.Background(), s.subscriber)
+	}
+
+	if s.started {
+		_ = s.client.Stop()
+	}
+}
+
+// resultTxFromEvent converts the Tx event into the same result the Tx RPC query returns.
+func resultTxFromEvent(event ctypes.ResultEvent) (*ctypes.ResultTx, bool) {
+	data, ok := event.Data.(tmtypes.EventDataTx)
+	if !ok {
+		return nil, false
+	}
+
+	return &ctypes.ResultTx{
+		Hash:     tmtypes.Tx(data.Tx).Hash(),
+		Height:   data.Height,
+		Index:    data.Index,
+		TxResult: data.Result,
+		Tx:       data.Tx,
+	}, true
+}
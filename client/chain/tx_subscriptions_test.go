@@ -0,0 +1,303 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+
+	"github.com/gotabit/sdk-go/client/common"
+)
+
+// fakeEventsClient stands in for the Tendermint websocket, it rejects more subscriptions
+// than maxSubscriptions the way Tendermint does, asynchronously without an error.
+type fakeEventsClient struct {
+	maxSubscriptions int
+
+	mux        sync.Mutex
+	running    bool
+	subscribes int
+	eventC     chan ctypes.ResultEvent
+}
+
+func newFakeEventsClient() *fakeEventsClient {
+	return &fakeEventsClient{maxSubscriptions: 5}
+}
+
+func (c *fakeEventsClient) IsRunning() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.running
+}
+
+func (c *fakeEventsClient) Start() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.running = true
+	return nil
+}
+
+func (c *fakeEventsClient) Stop() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.running = false
+	return nil
+}
+
+func (c *fakeEventsClient) Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan ctypes.ResultEvent, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.subscribes++
+	eventC := make(chan ctypes.ResultEvent, outCapacity[0])
+	if c.subscribes <= c.maxSubscriptions {
+		c.eventC = eventC
+	}
+
+	return eventC, nil
+}
+
+func (c *fakeEventsClient) UnsubscribeAll(ctx context.Context, subscriber string) error {
+	return nil
+}
+
+func (c *fakeEventsClient) subscribeCount() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return c.subscribes
+}
+
+// publish sends the Tx events of a block, like the Tendermint websocket does. Events that
+// don't fit the subscription buffer are dropped, their count is returned.
+func (c *fakeEventsClient) publish(height int64, txs ...[]byte) (dropped int) {
+	c.mux.Lock()
+	eventC := c.eventC
+	c.mux.Unlock()
+
+	for idx, txBytes := range txs {
+		select {
+		case eventC <- ctypes.ResultEvent{
+			Query: tmtypes.EventQueryTx.String(),
+			Data: tmtypes.EventDataTx{TxResult: abci.TxResult{
+				Height: height,
+				Index:  uint32(idx),
+				Tx:     txBytes,
+			}},
+		}:
+		default:
+			dropped++
+		}
+	}
+
+	return dropped
+}
+
+// drop closes the subscription, like an unsubscribe by the node.
+func (c *fakeEventsClient) drop() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	close(c.eventC)
+	c.eventC = nil
+}
+
+func txHashOf(txBytes []byte) string {
+	return fmt.Sprintf("%X", tmtypes.Tx(txBytes).Hash())
+}
+
+func TestTxSubscriptionsShareOneSubscription(t *testing.T) {
+	events := newFakeEventsClient()
+	subs := newTxSubscriptions(events)
+	defer subs.close()
+
+	// more Txs than Tendermint allows subscriptions per client
+	txs := make([][]byte, 0, 20)
+	resultCs := make([]<-chan *ctypes.ResultTx, 0, 20)
+	for i := 0; i < 20; i++ {
+		txBytes := []byte(fmt.Sprintf("tx-%d", i))
+		resultC, unsubscribe, err := subs.subscribe(context.Background(), txHashOf(txBytes))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer unsubscribe()
+
+		txs = append(txs, txBytes)
+		resultCs = append(resultCs, resultC)
+	}
+
+	if n := events.subscribeCount(); n != 1 {
+		t.Fatalf("expected a single subscription, got %d", n)
+	}
+
+	// events are dispatched by hash, in any order
+	for i := len(txs) - 1; i >= 0; i-- {
+		events.publish(42, txs[i])
+	}
+
+	for i, resultC := range resultCs {
+		select {
+		case resultTx := <-resultC:
+			if string(resultTx.Tx) != string(txs[i]) || resultTx.Height != 42 {
+				t.Fatalf("tx %d got the event of another tx: %s", i, resultTx.Tx)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("tx %d got no event", i)
+		}
+	}
+}
+
+func TestTxSubscriptionsResubscribeWhenClosed(t *testing.T) {
+	events := newFakeEventsClient()
+	subs := newTxSubscriptions(events)
+	defer subs.close()
+
+	if _, _, err := subs.subscribe(context.Background(), txHashOf([]byte("a"))); err != nil {
+		t.Fatal(err)
+	}
+
+	events.drop()
+	deadline := time.Now().Add(time.Second)
+	for events.subscribeCount() < 2 && time.Now().Before(deadline) {
+		if _, _, err := subs.subscribe(context.Background(), txHashOf([]byte("b"))); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := events.subscribeCount(); n != 2 {
+		t.Fatalf("expected to subscribe again once the subscription was closed, got %d subscriptions", n)
+	}
+}
+
+func TestTxSubscriptionsWithoutClient(t *testing.T) {
+	subs := newTxSubscriptions(nil)
+	defer subs.close()
+
+	if _, _, err := subs.subscribe(context.Background(), "AA"); err == nil {
+		t.Fatal("expected subscribing without tendermint client to fail")
+	}
+}
+
+func TestBroadcastAwaitsTxEvent(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	// inclusion can only be seen by its event within the broadcast timeout
+	c := newTestChainClient(t, signer, []*fakeNode{a},
+		common.OptionBroadcastStatusPoll(time.Second),
+		common.OptionBroadcastTimeout(5*time.Second),
+	)
+
+	events := newFakeEventsClient()
+	c.txSubs = newTxSubscriptions(events)
+	chain.onCommit = func(height int64, included []*fakeBroadcast) {
+		txs := make([][]byte, 0, len(included))
+		for _, broadcast := range included {
+			txs = append(txs, broadcast.txBytes)
+		}
+		go events.publish(height, txs...)
+	}
+
+	go func() {
+		for len(chain.sentBroadcasts()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		chain.commit()
+	}()
+
+	start := time.Now()
+	res, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if res.TxResponse.Height == 0 {
+		t.Fatalf("expected the tx to be included, got %+v", res.TxResponse)
+	} else if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the tx event to be awaited instead of polling, took %s", elapsed)
+	}
+}
+
+func TestBroadcastPollsWhenTxEventIsMissed(t *testing.T) {
+	chain := newFakeChain()
+	chain.setAutoCommit(true)
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a},
+		common.OptionBroadcastStatusPoll(10*time.Millisecond),
+		common.OptionBroadcastTimeout(5*time.Second),
+	)
+
+	// the subscriptions were rejected by the node, no event will ever arrive
+	events := newFakeEventsClient()
+	events.maxSubscriptions = 0
+	c.txSubs = newTxSubscriptions(events)
+
+	res, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if res.TxResponse.Height == 0 {
+		t.Fatalf("expected the tx to be found by polling, got %+v", res.TxResponse)
+	}
+}
+
+func TestBroadcastPollsWhenTxEventOverflows(t *testing.T) {
+	chain := newFakeChain()
+	a := startFakeNode(t, "aaa", chain)
+
+	signer := newTestSigner()
+	c := newTestChainClient(t, signer, []*fakeNode{a},
+		common.OptionBroadcastStatusPoll(10*time.Millisecond),
+		common.OptionBroadcastTimeout(5*time.Second),
+	)
+
+	events := newFakeEventsClient()
+	c.txSubs = newTxSubscriptions(events)
+
+	droppedC := make(chan int, 1)
+	chain.onCommit = func(height int64, included []*fakeBroadcast) {
+		// a busy block fills the buffer before the Tx of the client, while dispatching is stalled
+		txs := make([][]byte, 0, 2*txEventsCapacity+len(included))
+		for i := 0; i < 2*txEventsCapacity; i++ {
+			txs = append(txs, []byte(fmt.Sprintf("other-tx-%d", i)))
+		}
+		for _, broadcast := range included {
+			txs = append(txs, broadcast.txBytes)
+		}
+
+		go func() {
+			c.txSubs.mux.Lock()
+			droppedC <- events.publish(height, txs...)
+			c.txSubs.mux.Unlock()
+		}()
+	}
+
+	go func() {
+		for len(chain.sentBroadcasts()) == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+		chain.commit()
+	}()
+
+	res, err := c.BroadcastMsgs(context.Background(), txtypes.BroadcastMode_BROADCAST_MODE_BLOCK, newTestMsgSend(signer))
+	if err != nil {
+		t.Fatal(err)
+	} else if res.TxResponse.Height == 0 {
+		t.Fatalf("expected the tx to be found by polling, got %+v", res.TxResponse)
+	}
+
+	// at most one event more than the buffer holds is taken by the stalled dispatching
+	if dropped := <-droppedC; dropped < txEventsCapacity {
+		t.Fatalf("expected the tx event to overflow the buffer, %d events were dropped", dropped)
+	}
+}
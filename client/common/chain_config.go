@@ -0,0 +1,149 @@
+package common
+
+import (
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+	"github.com/pkg/errors"
+
+	ctypes "github.com/gotabit/sdk-go/chain/types"
+)
+
+// ChainConfig holds the address and key settings of a chain. Clients encode and decode
+// addresses with their own ChainConfig, so clients of different chains can live in one process.
+type ChainConfig struct {
+	// Bech32Prefix is the account address prefix, the other prefixes are derived from it
+	Bech32Prefix string
+	// CoinType is the BIP-44 coin type of the chain keys
+	CoinType uint32
+	// HDPath is the default HD path keys are derived at, e.g. "m/44'/60'/0'/0/0"
+	HDPath string
+	// FeeDenom is the preferred fee denom, and Decimals the number of its decimal places
+	FeeDenom string
+	Decimals int
+}
+
+// DefaultChainConfig returns the config of Injective, which is also applied to the global
+// sdk config for backwards compatibility.
+func DefaultChainConfig() ChainConfig {
+	return InjectiveChainConfig()
+}
+
+// InjectiveChainConfig returns the config of the Injective chain.
+func InjectiveChainConfig() ChainConfig {
+	return ChainConfig{
+		Bech32Prefix: ctypes.Bech32PrefixAccAddr,
+		CoinType:     ctypes.Bip44CoinType,
+		HDPath:       "m/44'/60'/0'/0/0",
+		FeeDenom:     "inj",
+		Decimals:     18,
+	}
+}
+
+// GotabitChainConfig returns the config of the Gotabit chain.
+func GotabitChainConfig() ChainConfig {
+	return ChainConfig{
+		Bech32Prefix: "gio",
+		CoinType:     sdk.CoinType,
+		HDPath:       sdk.FullFundraiserPath,
+		FeeDenom:     "ugtb",
+		Decimals:     6,
+	}
+}
+
+// Validate checks that the prefix can encode addresses and the fee denom is valid.
+func (c ChainConfig) Validate() error {
+	if len(c.Bech32Prefix) == 0 {
+		return errors.New("bech32 prefix is required")
+	} else if strings.TrimFunc(c.Bech32Prefix, isBech32PrefixRune) != "" {
+		return errors.Errorf("bech32 prefix %s must be lowercase alphanumeric", c.Bech32Prefix)
+	} else if _, err := bech32.ConvertAndEncode(c.Bech32PrefixValPub(), make([]byte, 32)); err != nil {
+		err = errors.Wrapf(err, "invalid bech32 prefix %s", c.Bech32Prefix)
+		return err
+	}
+
+	if err := sdk.ValidateDenom(c.FeeDenom); err != nil {
+		err = errors.Wrapf(err, "invalid fee denom %s", c.FeeDenom)
+		return err
+	} else if c.Decimals < 0 {
+		return errors.Errorf("fee denom decimals must not be negative, got %d", c.Decimals)
+	}
+
+	return nil
+}
+
+// Bech32PrefixAccPub returns the prefix of account public keys.
+func (c ChainConfig) Bech32PrefixAccPub() string {
+	return c.Bech32Prefix + sdk.PrefixPublic
+}
+
+// Bech32PrefixValAddr returns the prefix of validator operator addresses.
+func (c ChainConfig) Bech32PrefixValAddr() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixOperator
+}
+
+// Bech32PrefixValPub returns the prefix of validator operator public keys.
+func (c ChainConfig) Bech32PrefixValPub() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixOperator + sdk.PrefixPublic
+}
+
+// Bech32PrefixConsAddr returns the prefix of consensus node addresses.
+func (c ChainConfig) Bech32PrefixConsAddr() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixConsensus
+}
+
+// Bech32PrefixConsPub returns the prefix of consensus node public keys.
+func (c ChainConfig) Bech32PrefixConsPub() string {
+	return c.Bech32Prefix + sdk.PrefixValidator + sdk.PrefixConsensus + sdk.PrefixPublic
+}
+
+// FormatAccAddress encodes the account address with the chain prefix, unlike AccAddress.String
+// which uses the global sdk config. An empty address is encoded as an empty string.
+func (c ChainConfig) FormatAccAddress(addr sdk.AccAddress) string {
+	return sdk.MustBech32ifyAddressBytes(c.Bech32Prefix, addr)
+}
+
+// ParseAccAddress decodes the bech32 account address, which must have the chain prefix.
+func (c ChainConfig) ParseAccAddress(address string) (sdk.AccAddress, error) {
+	bz, err := sdk.GetFromBech32(address, c.Bech32Prefix)
+	if err != nil {
+		return nil, err
+	} else if err := sdk.VerifyAddressFormat(bz); err != nil {
+		return nil, err
+	}
+
+	return sdk.AccAddress(bz), nil
+}
+
+// FormatValAddress encodes the validator operator address with the chain prefix.
+func (c ChainConfig) FormatValAddress(addr sdk.ValAddress) string {
+	return sdk.MustBech32ifyAddressBytes(c.Bech32PrefixValAddr(), addr)
+}
+
+// ParseValAddress decodes the bech32 validator operator address, which must have the chain prefix.
+func (c ChainConfig) ParseValAddress(address string) (sdk.ValAddress, error) {
+	bz, err := sdk.GetFromBech32(address, c.Bech32PrefixValAddr())
+	if err != nil {
+		return nil, err
+	} else if err := sdk.VerifyAddressFormat(bz); err != nil {
+		return nil, err
+	}
+
+	return sdk.ValAddress(bz), nil
+}
+
+// SetSDKConfig applies the prefixes and coin type to the global sdk config, for processes
+// of a single chain that rely on AccAddress.String or Msg.ValidateBasic.
+// The global config must not be sealed.
+func (c ChainConfig) SetSDKConfig(config *sdk.Config) {
+	config.SetBech32PrefixForAccount(c.Bech32Prefix, c.Bech32PrefixAccPub())
+	config.SetBech32PrefixForValidator(c.Bech32PrefixValAddr(), c.Bech32PrefixValPub())
+	config.SetBech32PrefixForConsensusNode(c.Bech32PrefixConsAddr(), c.Bech32PrefixConsPub())
+	config.SetCoinType(c.CoinType)
+	config.SetFullFundraiserPath(c.HDPath)
+}
+
+func isBech32PrefixRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestChainConfigValidate(t *testing.T) {
+	for _, cfg := range []ChainConfig{InjectiveChainConfig(), GotabitChainConfig(), DefaultChainConfig()} {
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("expected the %s config to be valid: %v", cfg.Bech32Prefix, err)
+		}
+	}
+
+	for name, cfg := range map[string]ChainConfig{
+		"no prefix":         {FeeDenom: "ugtb"},
+		"uppercase prefix":  {Bech32Prefix: "GIO", FeeDenom: "ugtb"},
+		"invalid denom":     {Bech32Prefix: "gio", FeeDenom: "1"},
+		"negative decimals": {Bech32Prefix: "gio", FeeDenom: "ugtb", Decimals: -1},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Fatalf("expected the config with %s to be rejected", name)
+		}
+	}
+}
+
+func TestChainConfigAddresses(t *testing.T) {
+	gotabit, injective := GotabitChainConfig(), InjectiveChainConfig()
+	addr := sdk.AccAddress([]byte("01234567890123456789"))
+
+	encoded := gotabit.FormatAccAddress(addr)
+	if encoded[:4] != "gio1" {
+		t.Fatalf("expected a gio address, got %s", encoded)
+	}
+
+	parsed, err := gotabit.ParseAccAddress(encoded)
+	if err != nil {
+		t.Fatal(err)
+	} else if !parsed.Equals(addr) {
+		t.Fatalf("expected %s to be parsed back, got %s", encoded, parsed)
+	} else if _, err := injective.ParseAccAddress(encoded); err == nil {
+		t.Fatal("expected an address of another chain to be rejected")
+	}
+
+	valEncoded := gotabit.FormatValAddress(sdk.ValAddress(addr))
+	if valEncoded[:10] != "giovaloper" {
+		t.Fatalf("expected a giovaloper address, got %s", valEncoded)
+	}
+
+	valAddr, err := gotabit.ParseValAddress(valEncoded)
+	if err != nil {
+		t.Fatal(err)
+	} else if !valAddr.Equals(sdk.ValAddress(addr)) {
+		t.Fatalf("expected %s to be parsed back, got %s", valEncoded, valAddr)
+	} else if _, err := gotabit.ParseValAddress(encoded); err == nil {
+		t.Fatal("expected an account address to be rejected as validator address")
+	}
+}
+
+func TestChainConfigPrefixes(t *testing.T) {
+	cfg := GotabitChainConfig()
+
+	for expected, prefix := range map[string]string{
+		"giopub":        cfg.Bech32PrefixAccPub(),
+		"giovaloper":    cfg.Bech32PrefixValAddr(),
+		"giovaloperpub": cfg.Bech32PrefixValPub(),
+		"giovalcons":    cfg.Bech32PrefixConsAddr(),
+		"giovalconspub": cfg.Bech32PrefixConsPub(),
+	} {
+		if prefix != expected {
+			t.Fatalf("expected prefix %s, got %s", expected, prefix)
+		}
+	}
+
+	config := sdk.NewConfig()
+	cfg.SetSDKConfig(config)
+	if config.GetBech32AccountAddrPrefix() != "gio" || config.GetBech32ValidatorAddrPrefix() != "giovaloper" {
+		t.Fatalf("expected the gio prefixes, got %s %s", config.GetBech32AccountAddrPrefix(), config.GetBech32ValidatorAddrPrefix())
+	} else if config.GetCoinType() != cfg.CoinType || config.GetFullFundraiserPath() != cfg.HDPath {
+		t.Fatalf("expected the coin type %d and HD path %s", cfg.CoinType, cfg.HDPath)
+	}
+}
@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"path"
-	"runtime"
 	"strings"
 
 	"google.golang.org/grpc/credentials"
@@ -21,113 +19,72 @@ type Network struct {
 	ChainId              string
 	Fee_denom            string
 	Name                 string
+
+	// ChainConfig has the bech32 prefix and fee denom of the network chain
+	ChainConfig ChainConfig
+	// GasPrices are the fee token gas prices, e.g. for OptionGasPrices
+	GasPrices string
+	// Config is the declarative config the network was built from, with all its endpoints
+	Config NetworkConfig
 }
 
-func getFileAbsPath(relativePath string) string {
-	_, filename, _, _ := runtime.Caller(0)
-	return path.Join(path.Dir(filename), relativePath)
+// LoadNetwork loads the network by name from the embedded presets, e.g. "gotabit" or "gotabittestnet".
+// Use NetworkRegistry to load networks from user supplied files.
+//
+// LoadNetwork used to take the node as a second argument and return no error, see LoadNetworkNode.
+func LoadNetwork(name string) (Network, error) {
+	registry, err := NewNetworkRegistry()
+	if err != nil {
+		return Network{}, err
+	}
+
+	return registry.Network(name)
 }
 
-func LoadNetwork(name string, node string) Network {
-	if name == "devnet-1" {
-		return Network{
-			LcdEndpoint:          "https://devnet-1.lcd.injective.dev",
-			TmEndpoint:           "https://devnet-1.tm.injective.dev:443",
-			ChainGrpcEndpoint:    "tcp://devnet-1.grpc.injective.dev:9900",
-			ExchangeGrpcEndpoint: "tcp://devnet-1.api.injective.dev:9910",
-			ChainId:              "injective-777",
-			Fee_denom:            "inj",
-			Name:                 "devnet-1",
-		}
-	} else if name == "devnet" {
-		return Network{
-			LcdEndpoint:          "https://devnet.lcd.injective.dev",
-			TmEndpoint:           "https://devnet.tm.injective.dev:443",
-			ChainGrpcEndpoint:    "tcp://devnet.injective.dev:9900",
-			ExchangeGrpcEndpoint: "tcp://devnet.injective.dev:9910",
-			ChainId:              "injective-777",
-			Fee_denom:            "inj",
-			Name:                 "devnet",
-		}
-	} else if name == "testnet" {
-		validNodes := []string{"sentry0", "sentry1", "k8s"}
-		if !contains(validNodes, node) {
-			panic(fmt.Sprintf("invalid node %s for %s", node, name))
-		}
+// LoadNetworkNode loads the network by name with the endpoints of the node, e.g. "k8s" or "sentry0".
+// It returns an empty Network for unknown names and panics on unknown nodes.
+//
+// Deprecated: kept for the LoadNetwork(name, node) callers of earlier versions, use LoadNetwork instead.
+func LoadNetworkNode(name string, node string) Network {
+	registry, err := NewNetworkRegistry()
+	if err != nil {
+		panic(err)
+	}
 
-		var lcdEndpoint, tmEndpoint, chainGrpcEndpoint, exchangeGrpcEndpoint string
-		var chainTlsCert, exchangeTlsCert credentials.TransportCredentials
-		if node == "k8s" {
-			certPath := getFileAbsPath("../cert/testnet.crt")
-			lcdEndpoint = "https://k8s.testnet.lcd.injective.network"
-			tmEndpoint = "https://k8s.testnet.tm.injective.network:443"
-			chainGrpcEndpoint = "tcp://k8s.testnet.chain.grpc.injective.network:443"
-			chainTlsCert = LoadTlsCert(certPath, chainGrpcEndpoint)
-			exchangeGrpcEndpoint = "tcp://k8s.testnet.exchange.grpc.injective.network:443"
-			exchangeTlsCert = LoadTlsCert(certPath, exchangeGrpcEndpoint)
-		} else {
-			lcdEndpoint = fmt.Sprintf("http://%s.injective.dev:10337", node)
-			tmEndpoint = fmt.Sprintf("http://%s.injective.dev:26657", node)
-			chainGrpcEndpoint = fmt.Sprintf("tcp://%s.injective.dev:9900", node)
-			exchangeGrpcEndpoint = fmt.Sprintf("tcp://%s.injective.dev:9910", node)
-		}
+	cfg, err := registry.NetworkConfig(name)
+	if err != nil {
+		return Network{}
+	}
 
-		return Network{
-			LcdEndpoint:          lcdEndpoint,
-			TmEndpoint:           tmEndpoint,
-			ChainGrpcEndpoint:    chainGrpcEndpoint,
-			ChainTlsCert:         chainTlsCert,
-			ExchangeGrpcEndpoint: exchangeGrpcEndpoint,
-			ExchangeTlsCert:      exchangeTlsCert,
-			ChainId:              "injective-888",
-			Fee_denom:            "inj",
-			Name:                 "testnet",
-		}
-	} else if name == "mainnet" {
-		validNodes := []string{"k8s", "lb", "sentry0", "sentry1", "sentry2", "sentry3"}
-		if !contains(validNodes, node) {
+	// networks with a single node ignore it
+	if len(cfg.GRPC) > 1 {
+		cfg.GRPC = nodeEndpoints(cfg.GRPC, node)
+		cfg.RPC = nodeEndpoints(cfg.RPC, node)
+		cfg.LCD = nodeEndpoints(cfg.LCD, node)
+		cfg.ExchangeGRPC = nodeEndpoints(cfg.ExchangeGRPC, node)
+		if len(cfg.GRPC) == 0 {
 			panic(fmt.Sprintf("invalid node %s for %s", node, name))
 		}
-		var lcdEndpoint, tmEndpoint, chainGrpcEndpoint, exchangeGrpcEndpoint string
-		var chainTlsCert, exchangeTlsCert credentials.TransportCredentials
-		if node == "k8s" || node == "lb" {
-			certPath := getFileAbsPath("../cert/mainnet.crt")
-			lcdEndpoint = fmt.Sprintf("https://%s.mainnet.lcd.injective.network", node)
-			tmEndpoint = fmt.Sprintf("https://%s.mainnet.tm.injective.network:443", node)
-			chainGrpcEndpoint = fmt.Sprintf("tcp://%s.mainnet.chain.grpc.injective.network:443", node)
-			chainTlsCert = LoadTlsCert(certPath, chainGrpcEndpoint)
-			exchangeGrpcEndpoint = fmt.Sprintf("tcp://%s.mainnet.exchange.grpc.injective.network:443", node)
-			exchangeTlsCert = LoadTlsCert(certPath, exchangeGrpcEndpoint)
-		} else {
-			lcdEndpoint = fmt.Sprintf("http://%s.injective.network:10337", node)
-			tmEndpoint = fmt.Sprintf("http://%s.injective.network:26657", node)
-			chainGrpcEndpoint = fmt.Sprintf("tcp://%s.injective.network:9900", node)
-			exchangeGrpcEndpoint = fmt.Sprintf("tcp://%s.injective.network:9910", node)
-		}
+	}
 
-		return Network{
-			LcdEndpoint:          lcdEndpoint,
-			TmEndpoint:           tmEndpoint,
-			ChainGrpcEndpoint:    chainGrpcEndpoint,
-			ChainTlsCert:         chainTlsCert,
-			ExchangeGrpcEndpoint: exchangeGrpcEndpoint,
-			ExchangeTlsCert:      exchangeTlsCert,
-			ChainId:              "injective-1",
-			Fee_denom:            "inj",
-			Name:                 "mainnet",
-		}
+	network, err := cfg.Network()
+	if err != nil {
+		panic(err)
 	}
 
-	return Network{}
+	return network
 }
 
-func contains(s []string, e string) bool {
-	for _, a := range s {
-		if a == e {
-			return true
+// nodeEndpoints returns the endpoints whose host starts with the node name.
+func nodeEndpoints(endpoints []Endpoint, node string) []Endpoint {
+	var filtered []Endpoint
+	for _, endpoint := range endpoints {
+		if _, address := ProtocolAndAddress(endpoint.Address); strings.HasPrefix(address, node+".") {
+			filtered = append(filtered, endpoint)
 		}
 	}
-	return false
+
+	return filtered
 }
 
 func DialerFunc(ctx context.Context, addr string) (net.Conn, error) {
@@ -0,0 +1,478 @@
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"embed"
+	"net"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"gopkg.in/yaml.v2"
+)
+
+//go:embed networks/*.yaml
+var presetNetworks embed.FS
+
+// NetworkConfig is the declarative config of a network, loaded from YAML or JSON
+// in either this schema or the cosmos chain-registry chain.json format.
+type NetworkConfig struct {
+	Name         string     `json:"name" yaml:"name"`
+	ChainID      string     `json:"chain_id" yaml:"chain_id"`
+	Bech32Prefix string     `json:"bech32_prefix" yaml:"bech32_prefix"`
+	CoinType     uint32     `json:"coin_type" yaml:"coin_type"`
+	HDPath       string     `json:"hd_path" yaml:"hd_path"`
+	FeeTokens    []FeeToken `json:"fee_tokens" yaml:"fee_tokens"`
+
+	GRPC []Endpoint `json:"grpc" yaml:"grpc"`
+	RPC  []Endpoint `json:"rpc" yaml:"rpc"`
+	LCD  []Endpoint `json:"lcd" yaml:"lcd"`
+	// ExchangeGRPC are the gRPC endpoints of the Injective exchange API, if the network has one
+	ExchangeGRPC []Endpoint `json:"exchange_grpc" yaml:"exchange_grpc"`
+}
+
+// FeeToken is a denom fees can be paid in, with its gas price.
+type FeeToken struct {
+	Denom    string  `json:"denom" yaml:"denom"`
+	Decimals int     `json:"decimals" yaml:"decimals"`
+	GasPrice float64 `json:"gas_price" yaml:"gas_price"`
+}
+
+// Endpoint is a node endpoint, e.g. "tcp://grpc.example.com:443" for gRPC or
+// "https://rpc.example.com:443" for Tendermint RPC.
+type Endpoint struct {
+	Address string `json:"address" yaml:"address"`
+	// TLS enables secure transport, verified with the system roots unless CACert is set
+	TLS        bool   `json:"tls" yaml:"tls"`
+	ServerName string `json:"server_name" yaml:"server_name"`
+	// CACert is the path of the PEM encoded CA certificate of the endpoint
+	CACert string `json:"ca_cert" yaml:"ca_cert"`
+}
+
+// TransportCredentials returns the gRPC TLS credentials of the endpoint,
+// or nil when the endpoint doesn't use TLS.
+func (e Endpoint) TransportCredentials() (credentials.TransportCredentials, error) {
+	if !e.TLS {
+		return nil, nil
+	}
+
+	serverName := e.ServerName
+	if len(serverName) == 0 {
+		_, address := ProtocolAndAddress(e.Address)
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to get server name of %s", e.Address)
+			return nil, err
+		}
+		serverName = host
+	}
+
+	config := &tls.Config{
+		ServerName: serverName,
+	}
+
+	if len(e.CACert) > 0 {
+		pem, err := os.ReadFile(e.CACert)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to read CA cert of %s", e.Address)
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no PEM certificates in %s", e.CACert)
+		}
+	}
+
+	return credentials.NewTLS(config), nil
+}
+
+// ChainConfig returns the chain config of the network, with the first fee token as the fee denom.
+func (n NetworkConfig) ChainConfig() ChainConfig {
+	cfg := ChainConfig{
+		Bech32Prefix: n.Bech32Prefix,
+		CoinType:     n.CoinType,
+		HDPath:       n.HDPath,
+	}
+	if len(n.FeeTokens) > 0 {
+		cfg.FeeDenom = n.FeeTokens[0].Denom
+		cfg.Decimals = n.FeeTokens[0].Decimals
+	}
+
+	return cfg
+}
+
+// GasPrices returns the gas prices of the fee tokens, e.g. "0.0025ugtb".
+func (n NetworkConfig) GasPrices() string {
+	prices := make([]string, 0, len(n.FeeTokens))
+	for _, token := range n.FeeTokens {
+		if token.GasPrice > 0 {
+			prices = append(prices, strconv.FormatFloat(token.GasPrice, 'f', -1, 64)+token.Denom)
+		}
+	}
+
+	return strings.Join(prices, ",")
+}
+
+// Validate checks that the network has a chain ID, a valid chain config and endpoints.
+func (n NetworkConfig) Validate() error {
+	if len(n.Name) == 0 {
+		return errors.New("network name is required")
+	} else if len(n.ChainID) == 0 {
+		return errors.Errorf("network %s has no chain ID", n.Name)
+	} else if len(n.GRPC) == 0 && len(n.RPC) == 0 && len(n.LCD) == 0 {
+		return errors.Errorf("network %s has no endpoints", n.Name)
+	}
+
+	if err := n.ChainConfig().Validate(); err != nil {
+		err = errors.Wrapf(err, "invalid chain config of network %s", n.Name)
+		return err
+	}
+
+	if _, err := sdk.ParseDecCoins(n.GasPrices()); err != nil {
+		err = errors.Wrapf(err, "invalid fee tokens of network %s", n.Name)
+		return err
+	}
+
+	return nil
+}
+
+// Network builds the Network of the first endpoints, loading the TLS credentials of the gRPC endpoints.
+func (n NetworkConfig) Network() (Network, error) {
+	network := Network{
+		ChainId:     n.ChainID,
+		Name:        n.Name,
+		ChainConfig: n.ChainConfig(),
+		GasPrices:   n.GasPrices(),
+		Config:      n,
+	}
+	network.Fee_denom = network.ChainConfig.FeeDenom
+
+	if len(n.GRPC) > 0 {
+		tlsCert, err := n.GRPC[0].TransportCredentials()
+		if err != nil {
+			return Network{}, err
+		}
+
+		network.ChainGrpcEndpoint = n.GRPC[0].Address
+		network.ChainTlsCert = tlsCert
+	}
+	if len(n.RPC) > 0 {
+		network.TmEndpoint = n.RPC[0].Address
+	}
+	if len(n.LCD) > 0 {
+		network.LcdEndpoint = n.LCD[0].Address
+	}
+	if len(n.ExchangeGRPC) > 0 {
+		tlsCert, err := n.ExchangeGRPC[0].TransportCredentials()
+		if err != nil {
+			return Network{}, err
+		}
+
+		network.ExchangeGrpcEndpoint = n.ExchangeGRPC[0].Address
+		network.ExchangeTlsCert = tlsCert
+	}
+
+	return network, nil
+}
+
+// override sets the fields of other that are not empty.
+func (n NetworkConfig) override(other NetworkConfig) NetworkConfig {
+	if len(other.ChainID) > 0 {
+		n.ChainID = other.ChainID
+	}
+	if len(other.Bech32Prefix) > 0 {
+		n.Bech32Prefix = other.Bech32Prefix
+	}
+	if other.CoinType > 0 {
+		n.CoinType = other.CoinType
+	}
+	if len(other.HDPath) > 0 {
+		n.HDPath = other.HDPath
+	}
+	if len(other.FeeTokens) > 0 {
+		// chain-registry fee tokens have no decimals, keep the known ones
+		feeTokens := make([]FeeToken, 0, len(other.FeeTokens))
+		for _, token := range other.FeeTokens {
+			for _, known := range n.FeeTokens {
+				if token.Decimals == 0 && known.Denom == token.Denom {
+					token.Decimals = known.Decimals
+				}
+			}
+			feeTokens = append(feeTokens, token)
+		}
+		n.FeeTokens = feeTokens
+	}
+	if len(other.GRPC) > 0 {
+		n.GRPC = other.GRPC
+	}
+	if len(other.RPC) > 0 {
+		n.RPC = other.RPC
+	}
+	if len(other.LCD) > 0 {
+		n.LCD = other.LCD
+	}
+	if len(other.ExchangeGRPC) > 0 {
+		n.ExchangeGRPC = other.ExchangeGRPC
+	}
+
+	return n
+}
+
+// chainRegistryChain is the subset of the cosmos chain-registry chain.json format used for networks.
+type chainRegistryChain struct {
+	ChainName    string `yaml:"chain_name"`
+	ChainID      string `yaml:"chain_id"`
+	Bech32Prefix string `yaml:"bech32_prefix"`
+	Slip44       uint32 `yaml:"slip44"`
+	Fees         struct {
+		FeeTokens []struct {
+			Denom            string  `yaml:"denom"`
+			FixedMinGasPrice float64 `yaml:"fixed_min_gas_price"`
+			LowGasPrice      float64 `yaml:"low_gas_price"`
+			AverageGasPrice  float64 `yaml:"average_gas_price"`
+		} `yaml:"fee_tokens"`
+	} `yaml:"fees"`
+	APIs struct {
+		RPC  []chainRegistryEndpoint `yaml:"rpc"`
+		Rest []chainRegistryEndpoint `yaml:"rest"`
+		GRPC []chainRegistryEndpoint `yaml:"grpc"`
+	} `yaml:"apis"`
+}
+
+type chainRegistryEndpoint struct {
+	Address string `yaml:"address"`
+}
+
+func (c chainRegistryChain) networkConfig() NetworkConfig {
+	cfg := NetworkConfig{
+		Name:         c.ChainName,
+		ChainID:      c.ChainID,
+		Bech32Prefix: c.Bech32Prefix,
+		CoinType:     c.Slip44,
+	}
+	if c.Slip44 > 0 {
+		cfg.HDPath = "m/44'/" + strconv.FormatUint(uint64(c.Slip44), 10) + "'/0'/0/0"
+	}
+
+	for _, token := range c.Fees.FeeTokens {
+		gasPrice := token.AverageGasPrice
+		if gasPrice == 0 {
+			gasPrice = token.LowGasPrice
+		}
+		if gasPrice == 0 {
+			gasPrice = token.FixedMinGasPrice
+		}
+
+		cfg.FeeTokens = append(cfg.FeeTokens, FeeToken{
+			Denom:    token.Denom,
+			GasPrice: gasPrice,
+		})
+	}
+
+	for _, api := range c.APIs.GRPC {
+		cfg.GRPC = append(cfg.GRPC, chainRegistryGRPCEndpoint(api.Address))
+	}
+	for _, api := range c.APIs.RPC {
+		cfg.RPC = append(cfg.RPC, Endpoint{Address: api.Address})
+	}
+	for _, api := range c.APIs.Rest {
+		cfg.LCD = append(cfg.LCD, Endpoint{Address: api.Address})
+	}
+
+	return cfg
+}
+
+// chainRegistryGRPCEndpoint converts a chain-registry gRPC address, e.g. "grpc.example.com:443"
+// or "https://grpc.example.com", into a dialable endpoint. Port 443 and https imply TLS.
+func chainRegistryGRPCEndpoint(address string) Endpoint {
+	useTLS := strings.HasPrefix(address, "https://")
+	address = strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	address = strings.TrimSuffix(address, "/")
+
+	if _, port, err := net.SplitHostPort(address); err != nil {
+		port = "9090"
+		if useTLS {
+			port = "443"
+		}
+		address = net.JoinHostPort(address, port)
+	} else if port == "443" {
+		useTLS = true
+	}
+
+	return Endpoint{
+		Address: "tcp://" + address,
+		TLS:     useTLS,
+	}
+}
+
+// ParseNetworkConfigs parses networks from YAML or JSON, which is either a chain-registry chain.json,
+// a single network of the NetworkConfig schema, or a list of them under "networks".
+func ParseNetworkConfigs(data []byte) ([]NetworkConfig, error) {
+	var fields map[string]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		err = errors.Wrap(err, "failed to parse network config")
+		return nil, err
+	}
+
+	switch {
+	case fields["chain_name"] != nil:
+		var chain chainRegistryChain
+		if err := yaml.Unmarshal(data, &chain); err != nil {
+			err = errors.Wrap(err, "failed to parse chain-registry chain")
+			return nil, err
+		}
+
+		return []NetworkConfig{chain.networkConfig()}, nil
+
+	case fields["networks"] != nil:
+		var file struct {
+			Networks []NetworkConfig `yaml:"networks"`
+		}
+		if err := yaml.UnmarshalStrict(data, &file); err != nil {
+			err = errors.Wrap(err, "failed to parse networks")
+			return nil, err
+		}
+
+		return file.Networks, nil
+
+	default:
+		var network NetworkConfig
+		if err := yaml.UnmarshalStrict(data, &network); err != nil {
+			err = errors.Wrap(err, "failed to parse network")
+			return nil, err
+		}
+
+		return []NetworkConfig{network}, nil
+	}
+}
+
+// NetworkRegistry holds networks by name, starting with the embedded presets "gotabit", "gotabittestnet"
+// and the Injective "devnet-1", "devnet", "testnet" and "mainnet". Networks loaded later override the
+// fields they set, and other chains are added from their chain-registry chain.json.
+type NetworkRegistry struct {
+	mux      sync.RWMutex
+	networks map[string]NetworkConfig
+}
+
+// NewNetworkRegistry creates a registry with the embedded presets.
+func NewNetworkRegistry() (*NetworkRegistry, error) {
+	r := &NetworkRegistry{
+		networks: make(map[string]NetworkConfig),
+	}
+
+	entries, err := presetNetworks.ReadDir("networks")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		data, err := presetNetworks.ReadFile(path.Join("networks", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := r.Load(data); err != nil {
+			err = errors.Wrapf(err, "invalid network preset %s", entry.Name())
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// LoadFile loads the networks of a YAML or JSON file, see ParseNetworkConfigs.
+func (r *NetworkRegistry) LoadFile(filename string) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read network config")
+		return err
+	}
+
+	if err := r.Load(data); err != nil {
+		err = errors.Wrapf(err, "failed to load networks from %s", filename)
+		return err
+	}
+
+	return nil
+}
+
+// Load adds the networks parsed from data, overriding the fields of known networks with the same name.
+func (r *NetworkRegistry) Load(data []byte) error {
+	networks, err := ParseNetworkConfigs(data)
+	if err != nil {
+		return err
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	// validate all networks before adding any of them
+	merged := make([]NetworkConfig, 0, len(networks))
+	for _, network := range networks {
+		if known, ok := r.networks[network.Name]; ok {
+			network = known.override(network)
+		}
+
+		if err := network.Validate(); err != nil {
+			return err
+		}
+
+		merged = append(merged, network)
+	}
+
+	for _, network := range merged {
+		r.networks[network.Name] = network
+	}
+
+	return nil
+}
+
+// Names returns the sorted names of the networks.
+func (r *NetworkRegistry) Names() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	names := make([]string, 0, len(r.networks))
+	for name := range r.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NetworkConfig returns the config of the network by name or chain ID.
+func (r *NetworkRegistry) NetworkConfig(name string) (NetworkConfig, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+
+	if network, ok := r.networks[name]; ok {
+		return network, nil
+	}
+
+	for _, network := range r.networks {
+		if network.ChainID == name {
+			return network, nil
+		}
+	}
+
+	return NetworkConfig{}, errors.Errorf("unknown network %s", name)
+}
+
+// Network returns the network by name or chain ID.
+func (r *NetworkRegistry) Network(name string) (Network, error) {
+	cfg, err := r.NetworkConfig(name)
+	if err != nil {
+		return Network{}, err
+	}
+
+	return cfg.Network()
+}
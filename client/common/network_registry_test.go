@@ -0,0 +1,248 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// chainRegistryJSON is a trimmed chain-registry chain.json of a test chain.
+const chainRegistryJSON = `{
+  "$schema": "../chain.schema.json",
+  "chain_name": "testchain",
+  "chain_id": "testchain-1",
+  "bech32_prefix": "test",
+  "slip44": 118,
+  "fees": {
+    "fee_tokens": [
+      {"denom": "utest", "fixed_min_gas_price": 0.001, "average_gas_price": 0.0025}
+    ]
+  },
+  "apis": {
+    "rpc": [{"address": "https://rpc.testchain.example", "provider": "test"}],
+    "rest": [{"address": "https://lcd.testchain.example", "provider": "test"}],
+    "grpc": [
+      {"address": "grpc.testchain.example:443", "provider": "test"},
+      {"address": "grpc2.testchain.example:9090", "provider": "test"}
+    ]
+  }
+}`
+
+func TestLoadNetworkPresets(t *testing.T) {
+	for name, chainID := range map[string]string{
+		"devnet-1": "injective-777",
+		"devnet":   "injective-777",
+		"testnet":  "injective-888",
+		"mainnet":  "injective-1",
+	} {
+		network, err := LoadNetwork(name)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		} else if network.ChainId != chainID {
+			t.Fatalf("expected %s chain ID %s, got %s", name, chainID, network.ChainId)
+		} else if network.Fee_denom != "inj" || network.GasPrices != "500000000inj" {
+			t.Fatalf("unexpected %s fees %s %s", name, network.Fee_denom, network.GasPrices)
+		} else if network.ChainConfig != InjectiveChainConfig() {
+			t.Fatalf("unexpected %s chain config %+v", name, network.ChainConfig)
+		}
+	}
+
+	mainnet, err := LoadNetwork("mainnet")
+	if err != nil {
+		t.Fatal(err)
+	} else if mainnet.ChainGrpcEndpoint != "tcp://k8s.mainnet.chain.grpc.injective.network:443" || mainnet.ChainTlsCert == nil {
+		t.Fatalf("expected the mainnet k8s gRPC endpoint with TLS, got %s", mainnet.ChainGrpcEndpoint)
+	} else if len(mainnet.Config.GRPC) != 6 {
+		t.Fatalf("expected all mainnet gRPC endpoints, got %d", len(mainnet.Config.GRPC))
+	} else if mainnet.ExchangeGrpcEndpoint != "tcp://k8s.mainnet.exchange.grpc.injective.network:443" || mainnet.ExchangeTlsCert == nil {
+		t.Fatalf("expected the mainnet k8s exchange endpoint with TLS, got %s", mainnet.ExchangeGrpcEndpoint)
+	}
+
+	if _, err := LoadNetwork("injective-888"); err != nil {
+		t.Fatalf("expected the network to be found by chain ID: %v", err)
+	} else if _, err := LoadNetwork("unknown"); err == nil {
+		t.Fatal("expected an unknown network to be rejected")
+	}
+}
+
+func TestLoadNetworkGotabit(t *testing.T) {
+	for name, expected := range map[string]Network{
+		"gotabit": {
+			ChainId:           "grand-1",
+			ChainGrpcEndpoint: "tcp://grpc.gotabit.dev:443",
+			TmEndpoint:        "https://rpc.gotabit.dev:443",
+			LcdEndpoint:       "https://rest.gotabit.dev:443",
+		},
+		"gotabittestnet": {
+			ChainId:           "sandbox-2",
+			ChainGrpcEndpoint: "tcp://grpc-testnet.gotabit.dev:443",
+			TmEndpoint:        "https://rpc-testnet.gotabit.dev:443",
+			LcdEndpoint:       "https://rest-testnet.gotabit.dev:443",
+		},
+	} {
+		network, err := LoadNetwork(name)
+		if err != nil {
+			t.Fatalf("failed to load %s: %v", name, err)
+		} else if network.Name != name || network.ChainId != expected.ChainId {
+			t.Fatalf("expected %s chain ID %s, got %s %s", name, expected.ChainId, network.Name, network.ChainId)
+		} else if network.ChainGrpcEndpoint != expected.ChainGrpcEndpoint || network.ChainTlsCert == nil {
+			t.Fatalf("expected %s gRPC endpoint %s with TLS, got %s", name, expected.ChainGrpcEndpoint, network.ChainGrpcEndpoint)
+		} else if network.TmEndpoint != expected.TmEndpoint || network.LcdEndpoint != expected.LcdEndpoint {
+			t.Fatalf("unexpected %s endpoints %s %s", name, network.TmEndpoint, network.LcdEndpoint)
+		} else if network.ExchangeGrpcEndpoint != "" || network.ExchangeTlsCert != nil {
+			t.Fatalf("expected %s to have no exchange endpoint, got %s", name, network.ExchangeGrpcEndpoint)
+		} else if network.Fee_denom != "ugtb" || network.GasPrices != "0.0025ugtb" {
+			t.Fatalf("unexpected %s fees %s %s", name, network.Fee_denom, network.GasPrices)
+		} else if network.ChainConfig != GotabitChainConfig() {
+			t.Fatalf("unexpected %s chain config %+v", name, network.ChainConfig)
+		}
+	}
+}
+
+func TestLoadNetworkNode(t *testing.T) {
+	network := LoadNetworkNode("mainnet", "sentry2")
+	if network.ChainId != "injective-1" || network.ChainGrpcEndpoint != "tcp://sentry2.injective.network:9900" || network.ChainTlsCert != nil {
+		t.Fatalf("expected the sentry2 gRPC endpoint without TLS, got %s", network.ChainGrpcEndpoint)
+	} else if network.TmEndpoint != "http://sentry2.injective.network:26657" || network.LcdEndpoint != "http://sentry2.injective.network:10337" {
+		t.Fatalf("unexpected sentry2 endpoints %s %s", network.TmEndpoint, network.LcdEndpoint)
+	} else if network.ExchangeGrpcEndpoint != "tcp://sentry2.injective.network:9910" {
+		t.Fatalf("unexpected sentry2 exchange endpoint %s", network.ExchangeGrpcEndpoint)
+	}
+
+	if network := LoadNetworkNode("testnet", "k8s"); network.ExchangeGrpcEndpoint != "tcp://k8s.testnet.exchange.grpc.injective.network:443" || network.ExchangeTlsCert == nil {
+		t.Fatalf("expected the testnet k8s exchange endpoint with TLS, got %s", network.ExchangeGrpcEndpoint)
+	} else if network := LoadNetworkNode("devnet", "any"); network.ChainGrpcEndpoint != "tcp://devnet.injective.dev:9900" {
+		t.Fatalf("expected a single node network to ignore the node, got %s", network.ChainGrpcEndpoint)
+	} else if network := LoadNetworkNode("unknown", "k8s"); !reflect.DeepEqual(network, Network{}) {
+		t.Fatalf("expected an empty network for an unknown name, got %+v", network)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected an invalid node to panic")
+		}
+	}()
+	LoadNetworkNode("testnet", "sentry3")
+}
+
+func TestNetworkRegistryLoadsChainRegistryFile(t *testing.T) {
+	registry, err := NewNetworkRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "chain.json")
+	if err := os.WriteFile(filename, []byte(chainRegistryJSON), 0644); err != nil {
+		t.Fatal(err)
+	} else if err := registry.LoadFile(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := registry.NetworkConfig("testchain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NetworkConfig{
+		Name:         "testchain",
+		ChainID:      "testchain-1",
+		Bech32Prefix: "test",
+		CoinType:     118,
+		HDPath:       "m/44'/118'/0'/0/0",
+		FeeTokens:    []FeeToken{{Denom: "utest", GasPrice: 0.0025}},
+		GRPC: []Endpoint{
+			{Address: "tcp://grpc.testchain.example:443", TLS: true},
+			{Address: "tcp://grpc2.testchain.example:9090"},
+		},
+		RPC: []Endpoint{{Address: "https://rpc.testchain.example"}},
+		LCD: []Endpoint{{Address: "https://lcd.testchain.example"}},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Fatalf("unexpected network %+v", cfg)
+	}
+
+	if names := registry.Names(); !reflect.DeepEqual(names, []string{"devnet", "devnet-1", "gotabit", "gotabittestnet", "mainnet", "testchain", "testnet"}) {
+		t.Fatalf("unexpected networks %v", names)
+	}
+}
+
+func TestNetworkRegistryOverridesPresets(t *testing.T) {
+	registry, err := NewNetworkRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = registry.Load([]byte(`
+networks:
+  - name: testnet
+    grpc:
+      - address: tcp://localhost:9900
+    fee_tokens:
+      - denom: inj
+        gas_price: 160000000
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	network, err := registry.Network("testnet")
+	if err != nil {
+		t.Fatal(err)
+	} else if network.ChainId != "injective-888" || network.TmEndpoint != "https://k8s.testnet.tm.injective.network:443" {
+		t.Fatalf("expected the fields not set by the file to be kept, got %+v", network)
+	} else if network.ChainGrpcEndpoint != "tcp://localhost:9900" || network.ChainTlsCert != nil {
+		t.Fatalf("expected the gRPC endpoints to be overridden, got %s", network.ChainGrpcEndpoint)
+	} else if network.GasPrices != "160000000inj" || network.ChainConfig.Decimals != 18 {
+		t.Fatalf("expected the gas price to be overridden keeping the decimals, got %s %d", network.GasPrices, network.ChainConfig.Decimals)
+	}
+}
+
+func TestNetworkRegistryRejectsInvalidNetworks(t *testing.T) {
+	registry, err := NewNetworkRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string]string{
+		"no chain ID":    "name: local\nbech32_prefix: inj\nfee_tokens: [{denom: inj}]\ngrpc: [{address: tcp://localhost:9900}]",
+		"no endpoints":   "name: local\nchain_id: local-1\nbech32_prefix: inj\nfee_tokens: [{denom: inj}]",
+		"bad prefix":     "name: local\nchain_id: local-1\nbech32_prefix: INJ\nfee_tokens: [{denom: inj}]\ngrpc: [{address: tcp://localhost:9900}]",
+		"unknown fields": "name: local\nchain: local-1",
+	} {
+		if err := registry.Load([]byte(data)); err == nil {
+			t.Fatalf("expected the network with %s to be rejected", name)
+		}
+	}
+
+	// none of the networks is added when one of them is invalid
+	err = registry.Load([]byte(`
+networks:
+  - name: local
+    chain_id: local-1
+    bech32_prefix: inj
+    fee_tokens: [{denom: inj}]
+    grpc: [{address: tcp://localhost:9900}]
+  - name: mainnet
+    bech32_prefix: INJ
+`))
+	if err == nil {
+		t.Fatal("expected the invalid network to be rejected")
+	} else if _, err := registry.NetworkConfig("local"); err == nil {
+		t.Fatal("expected the valid network not to be added")
+	}
+}
+
+func TestChainRegistryGRPCEndpoint(t *testing.T) {
+	for address, expected := range map[string]Endpoint{
+		"grpc.example.com:443":       {Address: "tcp://grpc.example.com:443", TLS: true},
+		"grpc.example.com:9090":      {Address: "tcp://grpc.example.com:9090"},
+		"https://grpc.example.com/":  {Address: "tcp://grpc.example.com:443", TLS: true},
+		"http://grpc.example.com":    {Address: "tcp://grpc.example.com:9090"},
+		"https://grpc.example.com:9": {Address: "tcp://grpc.example.com:9", TLS: true},
+	} {
+		if endpoint := chainRegistryGRPCEndpoint(address); endpoint != expected {
+			t.Fatalf("expected %s to be converted to %+v, got %+v", address, expected, endpoint)
+		}
+	}
+}
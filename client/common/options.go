@@ -1,31 +1,72 @@
 package common
 
 import (
+	"time"
+
 	log "github.com/InjectiveLabs/suplog"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	ctypes "github.com/gotabit/sdk-go/chain/types"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/credentials"
 )
 
 func init() {
-	// set the address prefixes
-	config := sdk.GetConfig()
-
-	// This is specific to Injective chain
-	ctypes.SetBech32Prefixes(config)
-	ctypes.SetBip44CoinType(config)
+	// clients use their own ChainConfig, the global config only keeps
+	// AccAddress.String and Msg.ValidateBasic working as before
+	DefaultChainConfig().SetSDKConfig(sdk.GetConfig())
 }
 
+const (
+	DefaultBatchSizeLimit      = 1024
+	DefaultBatchTimeLimit      = 500 * time.Millisecond
+	DefaultBroadcastStatusPoll = 100 * time.Millisecond
+	DefaultBroadcastTimeout    = 40 * time.Second
+	DefaultTimeoutHeight       = 20
+	DefaultGasAdjustment       = 1.5
+
+	DefaultTimeoutHeightSyncInterval = 10 * time.Second
+)
+
 type ClientOptions struct {
-	GasPrices string
-	TLSCert   credentials.TransportCredentials
+	GasPrices          string
+	TLSCert            credentials.TransportCredentials
+	PipelinedBroadcast bool
+	Offline            bool
+
+	BatchSizeLimit      int
+	BatchTimeLimit      time.Duration
+	BroadcastStatusPoll time.Duration
+	BroadcastTimeout    time.Duration
+	TimeoutHeight       uint64
+	GasAdjustment       float64
+	MaxGasPerTx         uint64
+
+	TimeoutHeightSyncInterval time.Duration
+
+	FeeDenoms                []string
+	MaxFee                   sdk.Coins
+	GasPricesRefreshInterval time.Duration
+
+	ChainConfig ChainConfig
+	SessionDir  string
+	RetryPolicy RetryPolicy
 }
 
 type ClientOption func(opts *ClientOptions) error
 
 func DefaultClientOptions() *ClientOptions {
-	return &ClientOptions{}
+	return &ClientOptions{
+		BatchSizeLimit:      DefaultBatchSizeLimit,
+		BatchTimeLimit:      DefaultBatchTimeLimit,
+		BroadcastStatusPoll: DefaultBroadcastStatusPoll,
+		BroadcastTimeout:    DefaultBroadcastTimeout,
+		TimeoutHeight:       DefaultTimeoutHeight,
+		GasAdjustment:       DefaultGasAdjustment,
+
+		TimeoutHeightSyncInterval: DefaultTimeoutHeightSyncInterval,
+
+		ChainConfig: DefaultChainConfig(),
+		RetryPolicy: DefaultRetryPolicy(),
+	}
 }
 
 func OptionGasPrices(gasPrices string) ClientOption {
@@ -52,3 +93,192 @@ func OptionTLSCert(tlsCert credentials.TransportCredentials) ClientOption {
 		return nil
 	}
 }
+
+// OptionOffline creates a read-only client that doesn't connect to any node, to build
+// unsigned Txs on a machine without network access. The account number, sequence and
+// gas limit of those Txs must be provided, since they can't be queried or simulated.
+func OptionOffline() ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.Offline = true
+		return nil
+	}
+}
+
+// OptionPipelinedBroadcast makes the message queue broadcast batches back-to-back
+// without waiting for the previous batch to be included in block.
+func OptionPipelinedBroadcast(enabled bool) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.PipelinedBroadcast = enabled
+		return nil
+	}
+}
+
+// OptionBatchSizeLimit sets the max number of queued messages committed in a single Tx.
+func OptionBatchSizeLimit(limit int) ClientOption {
+	return func(opts *ClientOptions) error {
+		if limit <= 0 {
+			return errors.Errorf("batch size limit must be positive, got %d", limit)
+		}
+
+		opts.BatchSizeLimit = limit
+		return nil
+	}
+}
+
+// OptionBatchTimeLimit sets how long queued messages are collected before being committed.
+func OptionBatchTimeLimit(limit time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if limit <= 0 {
+			return errors.Errorf("batch time limit must be positive, got %s", limit)
+		}
+
+		opts.BatchTimeLimit = limit
+		return nil
+	}
+}
+
+// OptionBroadcastStatusPoll sets the interval of Tx inclusion polling.
+func OptionBroadcastStatusPoll(interval time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if interval <= 0 {
+			return errors.Errorf("broadcast status poll interval must be positive, got %s", interval)
+		}
+
+		opts.BroadcastStatusPoll = interval
+		return nil
+	}
+}
+
+// OptionBroadcastTimeout sets how long to wait for a broadcast Tx to be included in block.
+func OptionBroadcastTimeout(timeout time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if timeout <= 0 {
+			return errors.Errorf("broadcast timeout must be positive, got %s", timeout)
+		}
+
+		opts.BroadcastTimeout = timeout
+		return nil
+	}
+}
+
+// OptionTimeoutHeight sets the number of blocks after the latest one a Tx stays valid for.
+// Zero disables Tx timeout heights.
+func OptionTimeoutHeight(blocks uint64) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.TimeoutHeight = blocks
+		return nil
+	}
+}
+
+// OptionTimeoutHeightSyncInterval sets how often the latest block height Tx timeout heights
+// are derived from is refreshed.
+func OptionTimeoutHeightSyncInterval(interval time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if interval <= 0 {
+			return errors.Errorf("timeout height sync interval must be positive, got %s", interval)
+		}
+
+		opts.TimeoutHeightSyncInterval = interval
+		return nil
+	}
+}
+
+// OptionGasAdjustment sets the multiplier applied to the simulated gas usage.
+func OptionGasAdjustment(adjustment float64) ClientOption {
+	return func(opts *ClientOptions) error {
+		if adjustment < 1 {
+			return errors.Errorf("gas adjustment must be at least 1, got %v", adjustment)
+		}
+
+		opts.GasAdjustment = adjustment
+		return nil
+	}
+}
+
+// OptionMaxGasPerTx sets the max gas a single batch Tx may use, batches above it are split.
+// Zero derives the limit from the block max gas of the chain consensus params.
+func OptionMaxGasPerTx(gas uint64) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.MaxGasPerTx = gas
+		return nil
+	}
+}
+
+// OptionFeeDenoms sets the denoms fees are paid in, in the order of preference.
+func OptionFeeDenoms(denoms ...string) ClientOption {
+	return func(opts *ClientOptions) error {
+		for _, denom := range denoms {
+			if err := sdk.ValidateDenom(denom); err != nil {
+				err = errors.Wrapf(err, "invalid fee denom %s", denom)
+				return err
+			}
+		}
+
+		opts.FeeDenoms = denoms
+		return nil
+	}
+}
+
+// OptionMaxFee sets the max fee per Tx for each denom, Txs with higher estimated fees are not sent.
+func OptionMaxFee(maxFee string) ClientOption {
+	return func(opts *ClientOptions) error {
+		coins, err := sdk.ParseCoinsNormalized(maxFee)
+		if err != nil {
+			err = errors.Wrapf(err, "failed to ParseCoins %s", maxFee)
+			return err
+		}
+
+		opts.MaxFee = coins
+		return nil
+	}
+}
+
+// OptionGasPricesRefreshInterval enables querying the node minimum gas prices and sets how often
+// they are refreshed. The node config service is only served from cosmos-sdk v0.46 on, so querying
+// is disabled (zero) by default. The min gas prices of older nodes are learned from rejected Txs.
+func OptionGasPricesRefreshInterval(interval time.Duration) ClientOption {
+	return func(opts *ClientOptions) error {
+		if interval < 0 {
+			return errors.Errorf("gas prices refresh interval must not be negative, got %s", interval)
+		}
+
+		opts.GasPricesRefreshInterval = interval
+		return nil
+	}
+}
+
+// OptionChainConfig sets the bech32 prefixes, keys and fee denom of the chain the client talks to.
+// The fee denom is preferred for fees, unless fee denoms are set with OptionFeeDenoms.
+func OptionChainConfig(cfg ChainConfig) ClientOption {
+	return func(opts *ClientOptions) error {
+		if err := cfg.Validate(); err != nil {
+			err = errors.Wrap(err, "invalid chain config")
+			return err
+		}
+
+		opts.ChainConfig = cfg
+		return nil
+	}
+}
+
+// OptionSessionDir sets the dir the session affinity cookies of the gRPC endpoint are saved in,
+// so sticky sessions outlive the client. Cookies are only kept in memory when dir is empty.
+func OptionSessionDir(dir string) ClientOption {
+	return func(opts *ClientOptions) error {
+		opts.SessionDir = dir
+		return nil
+	}
+}
+
+// OptionRetryPolicy sets how failed gRPC calls of the client are retried.
+func OptionRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(opts *ClientOptions) error {
+		if err := policy.Validate(); err != nil {
+			err = errors.Wrap(err, "invalid retry policy")
+			return err
+		}
+
+		opts.RetryPolicy = policy
+		return nil
+	}
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 200 * time.Millisecond
+	DefaultRetryMaxBackoff     = 5 * time.Second
+	DefaultRetryMultiplier     = 2
+	DefaultRetryJitter         = 0.2
+)
+
+// RetryPolicy defines how failed gRPC calls are retried, with exponential backoff between attempts.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a call, including the first one. One disables retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, multiplied by Multiplier for every
+	// following retry up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each backoff by up to the given fraction of it, e.g. 0.2 for ±20%
+	Jitter float64
+	// Retryable classifies the errors of failed calls, the default classification is used when nil
+	Retryable func(err error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    DefaultRetryMaxAttempts,
+		InitialBackoff: DefaultRetryInitialBackoff,
+		MaxBackoff:     DefaultRetryMaxBackoff,
+		Multiplier:     DefaultRetryMultiplier,
+		Jitter:         DefaultRetryJitter,
+	}
+}
+
+// Validate checks that the policy makes at least one attempt and its backoff doesn't shrink.
+func (p RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 {
+		return errors.Errorf("max attempts must be at least 1, got %d", p.MaxAttempts)
+	} else if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return errors.Errorf("backoff must not be negative, got %s and max %s", p.InitialBackoff, p.MaxBackoff)
+	} else if p.Multiplier < 1 {
+		return errors.Errorf("backoff multiplier must be at least 1, got %v", p.Multiplier)
+	} else if p.Jitter < 0 || p.Jitter > 1 {
+		return errors.Errorf("jitter must be between 0 and 1, got %v", p.Jitter)
+	}
+
+	return nil
+}
+
+// Backoff returns the wait before retrying the given failed attempt, starting at 1.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		backoff *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
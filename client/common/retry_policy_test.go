@@ -0,0 +1,52 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	if err := DefaultRetryPolicy().Validate(); err != nil {
+		t.Fatalf("expected the default policy to be valid: %v", err)
+	}
+
+	for name, update := range map[string]func(p *RetryPolicy){
+		"no attempts":      func(p *RetryPolicy) { p.MaxAttempts = 0 },
+		"negative backoff": func(p *RetryPolicy) { p.InitialBackoff = -time.Second },
+		"shrinking":        func(p *RetryPolicy) { p.Multiplier = 0.5 },
+		"jitter above 1":   func(p *RetryPolicy) { p.Jitter = 1.5 },
+	} {
+		policy := DefaultRetryPolicy()
+		update(&policy)
+		if err := policy.Validate(); err == nil {
+			t.Fatalf("expected the policy with %s to be rejected", name)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     3,
+	}
+
+	for attempt, expected := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 300 * time.Millisecond,
+		3: 900 * time.Millisecond,
+		4: time.Second,
+	} {
+		if backoff := policy.Backoff(attempt); backoff != expected {
+			t.Fatalf("expected backoff %s after attempt %d, got %s", expected, attempt, backoff)
+		}
+	}
+
+	policy.Jitter = 0.2
+	for i := 0; i < 100; i++ {
+		if backoff := policy.Backoff(2); backoff < 240*time.Millisecond || backoff > 360*time.Millisecond {
+			t.Fatalf("expected the jittered backoff within 20%% of 300ms, got %s", backoff)
+		}
+	}
+}
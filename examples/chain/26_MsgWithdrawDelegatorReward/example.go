@@ -13,8 +13,11 @@ import (
 )
 
 func main() {
-	// network := common.LoadNetwork("mainnet", "k8s")
-	network := common.LoadNetwork("testnet", "k8s")
+	// network, err := common.LoadNetwork("mainnet")
+	network, err := common.LoadNetwork("testnet")
+	if err != nil {
+		panic(err)
+	}
 	tmRPC, err := rpchttp.New(network.TmEndpoint, "/websocket")
 
 	if err != nil {
@@ -78,5 +81,5 @@ func main() {
 		return
 	}
 
-	fmt.Println("gas fee:", gasFee, "INJ")
+	fmt.Println("gas fee:", gasFee.String())
 }
@@ -8,7 +8,10 @@ import (
 )
 
 func main() {
-	network := common.LoadNetwork("testnet", "k8s")
+	network, err := common.LoadNetwork("testnet")
+	if err != nil {
+		panic(err)
+	}
 	tmClient := tmclient.NewRPCClient(network.TmEndpoint)
 	clientCtx, cancelFn := context.WithCancel(context.Background())
 	defer cancelFn()